@@ -0,0 +1,69 @@
+package GoroutinePool
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff decides how long a worker waits before retrying a failed task.
+// attempt is the number of attempts that have failed so far, starting at 1.
+type Backoff interface {
+	NextDelay(attempt int) time.Duration
+}
+
+type noBackoff struct{}
+
+// NoBackoff retries immediately, which is the default behavior of the pool
+func NoBackoff() Backoff {
+	return noBackoff{}
+}
+
+func (noBackoff) NextDelay(int) time.Duration {
+	return 0
+}
+
+type constantBackoff struct {
+	delay time.Duration
+}
+
+// ConstantBackoff waits the same delay before every retry
+func ConstantBackoff(delay time.Duration) Backoff {
+	return constantBackoff{delay: delay}
+}
+
+func (b constantBackoff) NextDelay(int) time.Duration {
+	return b.delay
+}
+
+type exponentialBackoff struct {
+	base time.Duration
+	max  time.Duration
+}
+
+// ExponentialBackoff doubles the delay after every failed attempt, starting at
+// base and capped at max. The actual delay is jittered between half and all of it
+// so that tasks failing together do not retry in lockstep.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return exponentialBackoff{base: base, max: max}
+}
+
+func (b exponentialBackoff) NextDelay(attempt int) time.Duration {
+	if b.base <= 0 {
+		return 0
+	}
+	delay := b.base
+	for i := 1; i < attempt && (b.max <= 0 || delay < b.max); i++ {
+		// 没有上限时防止溢出
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64
+			break
+		}
+		delay *= 2
+	}
+	if b.max > 0 && delay > b.max {
+		delay = b.max
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
@@ -0,0 +1,114 @@
+package GoroutinePool
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 5 * time.Millisecond, 10 * time.Millisecond},
+		{2, 10 * time.Millisecond, 20 * time.Millisecond},
+		{3, 20 * time.Millisecond, 40 * time.Millisecond},
+		{4, 25 * time.Millisecond, 50 * time.Millisecond},
+		{10, 25 * time.Millisecond, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if delay := backoff.NextDelay(tt.attempt); delay < tt.min || delay > tt.max {
+				t.Fatalf("attempt %d: delay %v outside [%v, %v]", tt.attempt, delay, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestExponentialBackoffUncapped(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 0)
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 5 * time.Millisecond, 10 * time.Millisecond},
+		{2, 10 * time.Millisecond, 20 * time.Millisecond},
+		{4, 40 * time.Millisecond, 80 * time.Millisecond},
+		{8, 640 * time.Millisecond, 1280 * time.Millisecond},
+		{1000, math.MaxInt64 / 2, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if delay := backoff.NextDelay(tt.attempt); delay < tt.min || delay > tt.max {
+				t.Fatalf("attempt %d: delay %v outside [%v, %v]", tt.attempt, delay, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestRetryWithConstantBackoff(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	pool := NewGoroutinePool(1, WithRetryCount(2), WithBackoff(ConstantBackoff(delay)))
+	defer pool.Release()
+
+	var (
+		mu       sync.Mutex
+		attempts []time.Time
+	)
+	taskErr := errors.New("downstream unavailable")
+	_, err := pool.SubmitWithResult(func() (interface{}, error) {
+		mu.Lock()
+		attempts = append(attempts, time.Now())
+		mu.Unlock()
+		return nil, taskErr
+	}).Get()
+	if err != taskErr {
+		t.Fatalf("expected %v, got %v", taskErr, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(attempts))
+	}
+	for i := 1; i < len(attempts); i++ {
+		if gap := attempts[i].Sub(attempts[i-1]); gap < delay {
+			t.Fatalf("attempt %d started %v after the previous one, expected at least %v", i+1, gap, delay)
+		}
+	}
+}
+
+func TestReleaseInterruptsBackoff(t *testing.T) {
+	pool := NewGoroutinePool(1, WithRetryCount(1), WithBackoff(ConstantBackoff(time.Hour)))
+
+	failed := make(chan struct{})
+	var once sync.Once
+	pool.Submit(func() (interface{}, error) {
+		once.Do(func() { close(failed) })
+		return nil, errors.New("failed")
+	})
+	<-failed
+
+	if err := pool.ReleaseWithTimeout(time.Second); err != nil {
+		t.Fatalf("release was blocked by the backoff: %v", err)
+	}
+}
+
+func TestNilBackoffIgnored(t *testing.T) {
+	var attempts atomic.Int32
+	pool := NewGoroutinePool(1, WithRetryCount(2), WithBackoff(nil))
+	defer pool.Release()
+
+	_, err := pool.SubmitWithResult(func() (interface{}, error) {
+		attempts.Add(1)
+		return nil, errors.New("failed")
+	}).Get()
+	if err == nil || attempts.Load() != 3 {
+		t.Fatalf("expected 3 failed attempts without a backoff, got %d attempts and %v", attempts.Load(), err)
+	}
+}
@@ -0,0 +1,83 @@
+package GoroutinePool
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// TaskResult is the outcome of a single task
+type TaskResult struct {
+	Result interface{}
+	Err    error
+}
+
+// Batch tracks a group of tasks submitted together with SubmitAll
+type Batch struct {
+	results   []TaskResult
+	remaining atomic.Int64
+	done      chan struct{}
+}
+
+// SubmitAll submits tasks as one batch, blocking while the task queue is full.
+// Tasks that cannot be submitted because the pool has been released fail with ErrPoolReleased.
+func (pool *GoroutinePool) SubmitAll(tasks []Task) *Batch {
+	batch := &Batch{
+		results: make([]TaskResult, len(tasks)),
+		done:    make(chan struct{}),
+	}
+	batch.remaining.Store(int64(len(tasks)))
+	if len(tasks) == 0 {
+		close(batch.done)
+	}
+	for i, task := range tasks {
+		i := i
+		item := newTaskItem(task, nil)
+		item.onDone = func(result interface{}, err error) {
+			batch.complete(i, result, err)
+		}
+		if err := pool.enqueue(context.Background(), item); err != nil {
+			item.discard(err)
+		}
+	}
+	return batch
+}
+
+// SubmitOrdered submits tasks and returns their futures, where futures[i]
+// always belongs to tasks[i] regardless of completion order
+func (pool *GoroutinePool) SubmitOrdered(tasks []Task) []*Future {
+	futures := make([]*Future, len(tasks))
+	for i, task := range tasks {
+		futures[i] = pool.SubmitWithResult(task)
+	}
+	return futures
+}
+
+// Wait blocks until every task of the batch has finished
+func (b *Batch) Wait() {
+	<-b.done
+}
+
+// Results waits for the batch and returns the outcome of each task in submission order
+func (b *Batch) Results() []TaskResult {
+	<-b.done
+	return b.results
+}
+
+// Collect waits for the batch and returns the results and errors of the tasks
+// as two slices aligned with the submission order
+func (b *Batch) Collect() ([]interface{}, []error) {
+	<-b.done
+	results := make([]interface{}, len(b.results))
+	errs := make([]error, len(b.results))
+	for i, r := range b.results {
+		results[i], errs[i] = r.Result, r.Err
+	}
+	return results, errs
+}
+
+func (b *Batch) complete(i int, result interface{}, err error) {
+	b.results[i] = TaskResult{Result: result, Err: err}
+	if b.remaining.Add(-1) == 0 {
+		close(b.done)
+	}
+}
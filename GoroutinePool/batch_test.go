@@ -0,0 +1,88 @@
+package GoroutinePool
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSubmitAll(t *testing.T) {
+	pool := NewGoroutinePool(8)
+	defer pool.Release()
+
+	taskErr := errors.New("odd task")
+	tasks := make([]Task, 100)
+	for i := range tasks {
+		i := i
+		delay := time.Duration(rand.Intn(1000)) * time.Microsecond
+		tasks[i] = func() (interface{}, error) {
+			time.Sleep(delay)
+			if i%2 == 1 {
+				return i, taskErr
+			}
+			return i, nil
+		}
+	}
+
+	results := pool.SubmitAll(tasks).Results()
+	if len(results) != len(tasks) {
+		t.Fatalf("expected %d results, got %d", len(tasks), len(results))
+	}
+	for i, r := range results {
+		if r.Result != i {
+			t.Fatalf("result %d out of order: %v", i, r.Result)
+		}
+		if (i%2 == 1) != (r.Err == taskErr) {
+			t.Fatalf("result %d has unexpected error %v", i, r.Err)
+		}
+	}
+}
+
+func TestSubmitAllAfterRelease(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	pool.Release()
+
+	batch := pool.SubmitAll([]Task{func() (interface{}, error) { return nil, nil }})
+	batch.Wait()
+	if err := batch.Results()[0].Err; err != ErrPoolReleased {
+		t.Fatalf("expected ErrPoolReleased, got %v", err)
+	}
+}
+
+func randomSleepTasks(n int) []Task {
+	tasks := make([]Task, n)
+	for i := range tasks {
+		i := i
+		delay := time.Duration(rand.Intn(1000)) * time.Microsecond
+		tasks[i] = func() (interface{}, error) {
+			time.Sleep(delay)
+			return i * i, nil
+		}
+	}
+	return tasks
+}
+
+func TestSubmitOrdered(t *testing.T) {
+	pool := NewGoroutinePool(8)
+	defer pool.Release()
+
+	futures := pool.SubmitOrdered(randomSleepTasks(100))
+	for i, future := range futures {
+		if result, err := future.Get(); result != i*i || err != nil {
+			t.Fatalf("future %d returned (%v, %v)", i, result, err)
+		}
+	}
+}
+
+func TestBatchCollect(t *testing.T) {
+	pool := NewGoroutinePool(8)
+	defer pool.Release()
+
+	results, errs := pool.SubmitAll(randomSleepTasks(100)).Collect()
+	for i := range results {
+		if results[i] != i*i || errs[i] != nil {
+			t.Fatalf("slot %d holds (%v, %v)", i, results[i], errs[i])
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package GoroutinePool
+
+import "time"
+
+// Config is a snapshot of how a pool is configured, including the changes made
+// at runtime by Resize and SetRateLimit
+type Config struct {
+	MinWorkers int
+	MaxWorkers int
+	// Timeout is the timeout of every task attempt, zero if there is none
+	Timeout    time.Duration
+	RetryCount int
+	// QueueCapacity is the capacity of the task queue, -1 if it is unbounded
+	QueueCapacity int
+	// RateLimitInterval is the spacing between task starts, zero if there is no rate limit
+	RateLimitInterval time.Duration
+	// QueueTimeout is how long a task may wait in the queue, zero if it may wait forever
+	QueueTimeout      time.Duration
+	WorkerIdleTimeout time.Duration
+	AdjustInterval    time.Duration
+	ScaleUpThreshold  float64
+	ScaleDownPolicy   ScaleDownPolicy
+	RejectionPolicy   RejectionPolicy
+	DispatchOrder     DispatchOrder
+}
+
+// Config returns the current configuration of the pool
+func (pool *GoroutinePool) Config() Config {
+	minWorkers, maxWorkers := pool.workerBounds()
+	return Config{
+		MinWorkers:        minWorkers,
+		MaxWorkers:        maxWorkers,
+		Timeout:           pool.timeout,
+		RetryCount:        pool.retryCount,
+		QueueCapacity:     pool.GetTaskQueueCap(),
+		RateLimitInterval: pool.rateLimiter.getInterval(),
+		QueueTimeout:      pool.queueTimeout,
+		WorkerIdleTimeout: pool.workerIdleTimeout,
+		AdjustInterval:    pool.adjustInterval,
+		ScaleUpThreshold:  pool.scaleUpThreshold,
+		ScaleDownPolicy:   pool.scaleDownPolicy,
+		RejectionPolicy:   pool.rejectionPolicy,
+		DispatchOrder:     pool.dispatchOrder,
+	}
+}
+
+// GetMinWorkers 获取最小工作协程数量
+func (pool *GoroutinePool) GetMinWorkers() int {
+	minWorkers, _ := pool.workerBounds()
+	return minWorkers
+}
+
+// GetMaxWorkers 获取最大工作协程数量
+func (pool *GoroutinePool) GetMaxWorkers() int {
+	_, maxWorkers := pool.workerBounds()
+	return maxWorkers
+}
+
+// GetTimeout 获取任务的超时时间
+func (pool *GoroutinePool) GetTimeout() time.Duration {
+	return pool.timeout
+}
+
+// GetRetryCount 获取任务的重试次数
+func (pool *GoroutinePool) GetRetryCount() int {
+	return pool.retryCount
+}
+
+// workerBounds returns minWorkers and maxWorkers, which Resize may change at any time
+func (pool *GoroutinePool) workerBounds() (int, int) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	return pool.minWorkers, pool.maxWorkers
+}
@@ -0,0 +1,77 @@
+package GoroutinePool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	var pool Pool = NewGoroutinePool(8,
+		WithMinWorkers(2),
+		WithTimeout(time.Second),
+		WithRetryCount(3),
+		WithTaskQueueSize(16),
+		WithRateLimit(10, time.Second),
+		WithQueueTimeout(time.Minute),
+		WithWorkerIdleTimeout(time.Hour),
+		WithAdjustInterval(time.Millisecond),
+		WithScaleUpThreshold(2),
+		WithScaleDownPolicy(ScaleDownOne),
+		WithRejectionPolicy(RejectError),
+		WithDispatchOrder(DispatchFIFO),
+	)
+	defer pool.Release()
+
+	want := Config{
+		MinWorkers:        2,
+		MaxWorkers:        8,
+		Timeout:           time.Second,
+		RetryCount:        3,
+		QueueCapacity:     16,
+		RateLimitInterval: 100 * time.Millisecond,
+		QueueTimeout:      time.Minute,
+		WorkerIdleTimeout: time.Hour,
+		AdjustInterval:    time.Millisecond,
+		ScaleUpThreshold:  2,
+		ScaleDownPolicy:   ScaleDownOne,
+		RejectionPolicy:   RejectError,
+		DispatchOrder:     DispatchFIFO,
+	}
+	if got := pool.Config(); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if pool.GetMinWorkers() != 2 || pool.GetMaxWorkers() != 8 ||
+		pool.GetTimeout() != time.Second || pool.GetRetryCount() != 3 {
+		t.Fatalf("getters disagree with the options: min %d, max %d, timeout %v, retries %d",
+			pool.GetMinWorkers(), pool.GetMaxWorkers(), pool.GetTimeout(), pool.GetRetryCount())
+	}
+
+	// 运行时的调整同样反映在配置中
+	if err := pool.Resize(4, 16); err != nil {
+		t.Fatal(err)
+	}
+	pool.SetRateLimit(0, 0)
+	want.MinWorkers, want.MaxWorkers, want.RateLimitInterval = 4, 16, 0
+	if got := pool.Config(); got != want {
+		t.Fatalf("expected %+v after Resize and SetRateLimit, got %+v", want, got)
+	}
+	if pool.GetMinWorkers() != 4 || pool.GetMaxWorkers() != 16 {
+		t.Fatalf("expected the bounds set by Resize, got [%d, %d]", pool.GetMinWorkers(), pool.GetMaxWorkers())
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	pool := NewGoroutinePool(4, WithUnboundedQueue())
+	defer pool.Release()
+
+	want := Config{
+		MinWorkers:       4,
+		MaxWorkers:       4,
+		QueueCapacity:    -1,
+		AdjustInterval:   time.Second,
+		ScaleUpThreshold: defaultScaleUpThreshold,
+	}
+	if got := pool.Config(); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
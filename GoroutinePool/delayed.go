@@ -0,0 +1,80 @@
+package GoroutinePool
+
+import (
+	"context"
+	"time"
+)
+
+// DelayedTask is returned by SubmitAfter and SubmitAt. It embeds the Future of
+// the task and can withdraw the task until it starts running.
+type DelayedTask struct {
+	*Future
+	pool  *GoroutinePool
+	item  *taskItem
+	timer *time.Timer
+}
+
+// SubmitAfter submits task to the pool once delay has elapsed.
+// If the pool is released before then, the task is dropped and its Future fails with ErrPoolReleased.
+func (pool *GoroutinePool) SubmitAfter(task Task, delay time.Duration) *DelayedTask {
+	item := newTaskItem(task, nil)
+	item.future = newFuture()
+	d := &DelayedTask{Future: item.future, pool: pool, item: item}
+
+	pool.delayedLock.Lock()
+	defer pool.delayedLock.Unlock()
+	if pool.IsReleased() {
+		item.discard(ErrPoolReleased)
+		return d
+	}
+	pool.delayed[d] = struct{}{}
+	d.timer = time.AfterFunc(delay, d.fire)
+	return d
+}
+
+// SubmitAt submits task to the pool at the given time, see SubmitAfter
+func (pool *GoroutinePool) SubmitAt(task Task, at time.Time) *DelayedTask {
+	return pool.SubmitAfter(task, time.Until(at))
+}
+
+// Cancel withdraws the task if it has not started running yet and returns true;
+// its Future then fails with ErrTaskCancelled
+func (d *DelayedTask) Cancel() bool {
+	d.pool.delayedLock.Lock()
+	if _, ok := d.pool.delayed[d]; ok {
+		delete(d.pool.delayed, d)
+		d.timer.Stop()
+	}
+	d.pool.delayedLock.Unlock()
+	// 已经入队但尚未开始执行的任务同样可以撤回，且不再计入队列长度
+	if d.item.discard(ErrTaskCancelled) {
+		d.pool.withdraw(d.item)
+		return true
+	}
+	return false
+}
+
+func (d *DelayedTask) fire() {
+	d.pool.delayedLock.Lock()
+	_, ok := d.pool.delayed[d]
+	delete(d.pool.delayed, d)
+	d.pool.delayedLock.Unlock()
+	if !ok {
+		// 已被取消或协程池已释放
+		return
+	}
+	if err := d.pool.enqueue(context.Background(), d.item); err != nil {
+		d.item.discard(err)
+	}
+}
+
+// dropDelayed discards every delayed task that has not been submitted yet
+func (pool *GoroutinePool) dropDelayed() {
+	pool.delayedLock.Lock()
+	defer pool.delayedLock.Unlock()
+	for d := range pool.delayed {
+		d.timer.Stop()
+		d.item.discard(ErrPoolReleased)
+	}
+	clear(pool.delayed)
+}
@@ -0,0 +1,96 @@
+package GoroutinePool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubmitAfter(t *testing.T) {
+	pool := NewGoroutinePool(2)
+	defer pool.Release()
+
+	const delay = 50 * time.Millisecond
+	begin := time.Now()
+	var startedAt time.Time
+	delayed := pool.SubmitAfter(func() (interface{}, error) {
+		startedAt = time.Now()
+		return "late", nil
+	}, delay)
+
+	select {
+	case <-delayed.Done():
+		t.Fatal("delayed task finished before its delay")
+	case <-time.After(delay / 2):
+	}
+	result, err := delayed.Get()
+	if result != "late" || err != nil {
+		t.Fatalf("got (%v, %v)", result, err)
+	}
+	if elapsed := startedAt.Sub(begin); elapsed < delay || elapsed > delay+200*time.Millisecond {
+		t.Fatalf("delayed task started after %v, expected shortly after %v", elapsed, delay)
+	}
+
+	result, err = pool.SubmitAt(func() (interface{}, error) {
+		return "at", nil
+	}, time.Now().Add(10*time.Millisecond)).Get()
+	if result != "at" || err != nil {
+		t.Fatalf("SubmitAt returned (%v, %v)", result, err)
+	}
+}
+
+func TestCancelDelayedTask(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	defer pool.Release()
+
+	delayed := pool.SubmitAfter(func() (interface{}, error) {
+		t.Error("cancelled delayed task was executed")
+		return nil, nil
+	}, 20*time.Millisecond)
+	if !delayed.Cancel() {
+		t.Fatal("expected Cancel to withdraw the delayed task")
+	}
+	if _, err := delayed.Get(); err != ErrTaskCancelled {
+		t.Fatalf("expected ErrTaskCancelled, got %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+}
+
+func TestCancelQueuedDelayedTask(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	defer pool.Release()
+	unblock := blockWorker(pool)
+	defer unblock()
+
+	// 第一个任务在 dispatch 中等待worker，第二个留在队列中
+	pool.Submit(func() (interface{}, error) { return nil, nil })
+	delayed := pool.SubmitAfter(func() (interface{}, error) {
+		t.Error("cancelled delayed task was executed")
+		return nil, nil
+	}, 0)
+	for pool.GetTaskQueueLen() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if !delayed.Cancel() {
+		t.Fatal("expected Cancel to withdraw the queued delayed task")
+	}
+	if n := pool.GetTaskQueueLen(); n != 0 {
+		t.Fatalf("expected the cancelled task to leave the queue length, got %d", n)
+	}
+}
+
+func TestReleaseDropsDelayedTasks(t *testing.T) {
+	pool := NewGoroutinePool(1)
+
+	delayed := pool.SubmitAfter(func() (interface{}, error) {
+		t.Error("delayed task ran after Release")
+		return nil, nil
+	}, time.Hour)
+	pool.Release()
+
+	if _, err := delayed.Get(); err != ErrPoolReleased {
+		t.Fatalf("expected ErrPoolReleased, got %v", err)
+	}
+	if _, err := pool.SubmitAfter(func() (interface{}, error) { return nil, nil }, 0).Get(); err != ErrPoolReleased {
+		t.Fatalf("expected SubmitAfter on a released pool to fail, got %v", err)
+	}
+}
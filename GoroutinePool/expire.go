@@ -0,0 +1,39 @@
+package GoroutinePool
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTaskExpired fails the Future of a task that waited in the queue for longer than the queue timeout
+var ErrTaskExpired = errors.New("task expired in the queue")
+
+// setExpiry stamps item with the time after which dispatch discards it instead
+// of running it: the queue timeout from now, or the deadline of ctx if that is
+// earlier. Items of a pool without a queue timeout never expire.
+func (pool *GoroutinePool) setExpiry(ctx context.Context, item *taskItem) {
+	if pool.queueTimeout <= 0 {
+		return
+	}
+	item.expiry = time.Now().Add(pool.queueTimeout)
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(item.expiry) {
+		item.expiry = deadline
+	}
+}
+
+// expire discards item if it has expired. It returns false, leaving the item
+// alone, if it has not or if it has already been cancelled or started.
+func (pool *GoroutinePool) expire(item *taskItem) bool {
+	if item.expiry.IsZero() || time.Now().Before(item.expiry) {
+		return false
+	}
+	if !item.discard(ErrTaskExpired) {
+		return false
+	}
+	pool.stats.expired.Add(1)
+	if pool.expiredCallback != nil {
+		pool.expiredCallback(item.task)
+	}
+	return true
+}
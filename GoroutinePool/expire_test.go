@@ -0,0 +1,91 @@
+package GoroutinePool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueueTimeout(t *testing.T) {
+	var (
+		executed atomic.Int32
+		mu       sync.Mutex
+		expired  []Task
+	)
+	pool := NewGoroutinePool(1,
+		WithQueueTimeout(10*time.Millisecond),
+		WithExpiredCallback(func(task Task) {
+			mu.Lock()
+			expired = append(expired, task)
+			mu.Unlock()
+		}),
+	)
+	defer pool.Release()
+	unblock := blockWorker(pool)
+
+	// 第一个任务在 dispatch 中等待worker，其余的在队列中
+	futures := make([]*Future, 3)
+	for i := range futures {
+		futures[i] = pool.SubmitWithResult(func() (interface{}, error) {
+			executed.Add(1)
+			return nil, nil
+		})
+	}
+	time.Sleep(30 * time.Millisecond)
+	unblock()
+
+	for i, future := range futures {
+		if _, err := future.Get(); err != ErrTaskExpired {
+			t.Fatalf("task %d: expected ErrTaskExpired, got %v", i, err)
+		}
+	}
+	pool.Wait()
+	if n := executed.Load(); n != 0 {
+		t.Fatalf("expected no expired task to run, %d did", n)
+	}
+	mu.Lock()
+	if len(expired) != 3 {
+		t.Fatalf("expected the expired callback for 3 tasks, got %d", len(expired))
+	}
+	mu.Unlock()
+	if stats := pool.Stats(); stats.ExpiredTasks != 3 || stats.CompletedTasks != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// 没有等待过久的任务照常执行
+	if _, err := pool.SubmitWithResult(func() (interface{}, error) {
+		executed.Add(1)
+		return nil, nil
+	}).Get(); err != nil || executed.Load() != 1 {
+		t.Fatalf("expected a fresh task to run, got %v", err)
+	}
+}
+
+func TestQueueTimeoutContextDeadline(t *testing.T) {
+	var executed atomic.Bool
+	pool := NewGoroutinePool(1, WithQueueTimeout(time.Hour))
+	defer pool.Release()
+	unblock := blockWorker(pool)
+
+	// ctx 的截止时间早于队列超时，以截止时间为准
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.SubmitWithContext(ctx, func() (interface{}, error) {
+		executed.Store(true)
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-ctx.Done()
+	unblock()
+	pool.Wait()
+
+	if executed.Load() {
+		t.Fatal("expected the task to expire at the deadline of its context")
+	}
+	if n := pool.Stats().ExpiredTasks; n != 1 {
+		t.Fatalf("expected 1 expired task, got %d", n)
+	}
+}
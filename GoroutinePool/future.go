@@ -0,0 +1,46 @@
+package GoroutinePool
+
+import "context"
+
+// Future represents the outcome of a task submitted with SubmitWithResult.
+// The outcome is recorded once, so every Get returns the same result and error.
+type Future struct {
+	done   chan struct{}
+	result interface{}
+	err    error
+}
+
+func newFuture() *Future {
+	return &Future{
+		done: make(chan struct{}),
+	}
+}
+
+// Done returns a channel that is closed once the task has finished
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get blocks until the task has finished and returns its result and error
+func (f *Future) Get() (interface{}, error) {
+	<-f.done
+	return f.result, f.err
+}
+
+// GetWithContext is like Get but gives up with ctx.Err() when ctx is done first
+func (f *Future) GetWithContext(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
+		return f.result, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// complete records the outcome of the task and wakes every waiter.
+// It must be called exactly once.
+func (f *Future) complete(result interface{}, err error) {
+	f.result = result
+	f.err = err
+	close(f.done)
+}
@@ -108,3 +108,19 @@ func BenchmarkAnts(b *testing.B) {
 	}
 	b.StopTimer()
 }
+
+func BenchmarkGoPoolWaitLatency(b *testing.B) {
+	pool := NewGoroutinePool(1)
+	defer pool.Release()
+
+	taskFunc := func() (interface{}, error) {
+		return nil, nil
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pool.Submit(taskFunc)
+		pool.Wait()
+	}
+	b.StopTimer()
+}
@@ -0,0 +1,52 @@
+package GoroutinePool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// TaskGroup is a set of tasks sharing a pool that can be waited on independently
+// of the other work in the pool
+type TaskGroup struct {
+	pool *GoroutinePool
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup creates an empty task group running on the pool
+func (pool *GoroutinePool) NewGroup() *TaskGroup {
+	return &TaskGroup{pool: pool}
+}
+
+// Submit submits a task as part of the group, blocking while the task queue is full.
+// It returns ErrPoolReleased once the pool has been released.
+func (g *TaskGroup) Submit(task Task) error {
+	g.wg.Add(1)
+	item := newTaskItem(task, nil)
+	item.onDone = g.done
+	if err := g.pool.enqueue(context.Background(), item); err != nil {
+		g.wg.Done()
+		return err
+	}
+	return nil
+}
+
+// Wait blocks until every task submitted to the group has finished and returns
+// the errors of the failed tasks joined together, or nil if all succeeded
+func (g *TaskGroup) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.errs...)
+}
+
+func (g *TaskGroup) done(_ interface{}, err error) {
+	if err != nil {
+		g.mu.Lock()
+		g.errs = append(g.errs, err)
+		g.mu.Unlock()
+	}
+	g.wg.Done()
+}
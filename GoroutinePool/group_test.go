@@ -0,0 +1,54 @@
+package GoroutinePool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskGroupsWaitIndependently(t *testing.T) {
+	pool := NewGoroutinePool(4)
+	defer pool.Release()
+
+	slow := pool.NewGroup()
+	unblock := make(chan struct{})
+	slow.Submit(func() (interface{}, error) {
+		<-unblock
+		return nil, nil
+	})
+
+	fast := pool.NewGroup()
+	taskErr := errors.New("fast task failed")
+	for i := 0; i < 10; i++ {
+		i := i
+		fast.Submit(func() (interface{}, error) {
+			if i == 3 {
+				return nil, taskErr
+			}
+			return nil, nil
+		})
+	}
+
+	fastDone := make(chan error)
+	go func() { fastDone <- fast.Wait() }()
+	select {
+	case err := <-fastDone:
+		if !errors.Is(err, taskErr) {
+			t.Fatalf("expected the fast group to report %v, got %v", taskErr, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("fast group waited for the slow group")
+	}
+
+	slowDone := make(chan error)
+	go func() { slowDone <- slow.Wait() }()
+	select {
+	case <-slowDone:
+		t.Fatal("slow group returned before its task finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(unblock)
+	if err := <-slowDone; err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
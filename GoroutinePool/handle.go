@@ -0,0 +1,41 @@
+package GoroutinePool
+
+import "context"
+
+// TaskHandle is returned by SubmitCancellable. It embeds the Future of the task
+// and can withdraw the task or cancel its context.
+type TaskHandle struct {
+	*Future
+	pool *GoroutinePool
+	item *taskItem
+}
+
+// SubmitCancellable submits a task that can later be cancelled through the returned handle.
+// If the pool has been released the handle's Future completes with ErrPoolReleased.
+func (pool *GoroutinePool) SubmitCancellable(task TaskCtx, opts ...TaskOption) *TaskHandle {
+	ctx, cancel := context.WithCancel(context.Background())
+	item := newTaskItem(func() (interface{}, error) {
+		return task(ctx)
+	}, opts)
+	item.future = newFuture()
+	item.cancel = cancel
+	if err := pool.enqueue(context.Background(), item); err != nil {
+		item.discard(err)
+	}
+	return &TaskHandle{Future: item.future, pool: pool, item: item}
+}
+
+// Cancel withdraws the task if it has not started yet and returns true; the task
+// then never runs, invokes no callbacks and its Future fails with ErrTaskCancelled.
+// A withdrawn task no longer counts towards the queue length and is skipped by
+// dispatch without taking a rate limit slot or a worker.
+// If the task is already running, Cancel cancels its context and returns false.
+// Cancelling a finished task has no effect.
+func (h *TaskHandle) Cancel() bool {
+	if h.item.discard(ErrTaskCancelled) {
+		h.pool.withdraw(h.item)
+		return true
+	}
+	h.item.cancel()
+	return false
+}
@@ -0,0 +1,123 @@
+package GoroutinePool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCancelBeforeStart(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	defer pool.Release()
+
+	unblock := blockWorker(pool)
+
+	handle := pool.SubmitCancellable(func(ctx context.Context) (interface{}, error) {
+		t.Error("cancelled task was executed")
+		return nil, nil
+	})
+	if !handle.Cancel() {
+		t.Fatal("expected Cancel to withdraw a queued task")
+	}
+	unblock()
+
+	if _, err := handle.Get(); err != ErrTaskCancelled {
+		t.Fatalf("expected ErrTaskCancelled, got %v", err)
+	}
+	// make sure the worker has moved past the cancelled task
+	pool.SubmitWithResult(func() (interface{}, error) { return nil, nil }).Get()
+	if stats := pool.Stats(); stats.FailedTasks != 0 || stats.CompletedTasks != 2 {
+		t.Fatalf("cancelled task was counted: %+v", stats)
+	}
+}
+
+func TestCancelDuringRun(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	defer pool.Release()
+
+	started := make(chan struct{})
+	handle := pool.SubmitCancellable(func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+
+	if handle.Cancel() {
+		t.Fatal("Cancel reported a running task as withdrawn")
+	}
+	if _, err := handle.Get(); err != context.Canceled {
+		t.Fatalf("expected the task to observe context.Canceled, got %v", err)
+	}
+}
+
+func TestCancelAfterComplete(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	defer pool.Release()
+
+	handle := pool.SubmitCancellable(func(ctx context.Context) (interface{}, error) {
+		return "done", nil
+	})
+	if result, err := handle.Get(); result != "done" || err != nil {
+		t.Fatalf("got (%v, %v)", result, err)
+	}
+	if handle.Cancel() {
+		t.Fatal("Cancel reported a finished task as withdrawn")
+	}
+	if result, err := handle.Get(); result != "done" || err != nil {
+		t.Fatalf("Cancel changed the outcome of a finished task to (%v, %v)", result, err)
+	}
+}
+
+func TestCancelledTaskLeavesQueue(t *testing.T) {
+	pool := NewGoroutinePool(1, WithRateLimit(1, time.Hour))
+	defer pool.Release()
+
+	// 第一个任务用掉限流名额
+	pool.SubmitWithResult(func() (interface{}, error) { return nil, nil }).Get()
+
+	handles := make([]*TaskHandle, 3)
+	for i := range handles {
+		handles[i] = pool.SubmitCancellable(func(ctx context.Context) (interface{}, error) {
+			t.Error("cancelled task was executed")
+			return nil, nil
+		})
+	}
+	// dispatch 可能已取走一个任务，正在等待限流
+	deadline := time.Now().Add(time.Second)
+	for pool.GetTaskQueueLen() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	for _, h := range handles {
+		if !h.Cancel() {
+			t.Fatal("expected Cancel to withdraw a queued task")
+		}
+	}
+	if n := pool.GetTaskQueueLen(); n != 0 {
+		t.Fatalf("expected cancelled tasks to leave the queue length, got %d", n)
+	}
+	if n := pool.Stats().CurrentQueueLength; n != 0 {
+		t.Fatalf("expected cancelled tasks to leave the stats queue length, got %d", n)
+	}
+}
+
+func TestCancelledTaskSkipsRateLimit(t *testing.T) {
+	pool := NewGoroutinePool(1, WithRateLimit(1, 100*time.Millisecond))
+	defer pool.Release()
+
+	unblock := blockWorker(pool)
+
+	cancelled := pool.SubmitCancellable(func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+	future := pool.SubmitWithResult(func() (interface{}, error) { return nil, nil })
+	cancelled.Cancel()
+	unblock()
+
+	begin := time.Now()
+	future.Get()
+	// 被取消的任务若占用名额，下一个任务要再等一个周期
+	if elapsed := time.Since(begin); elapsed > 150*time.Millisecond {
+		t.Fatalf("a cancelled task used up a rate limit slot, next task took %v", elapsed)
+	}
+}
@@ -0,0 +1,144 @@
+package GoroutinePool
+
+import "context"
+
+// SubmitKeyed submits a task that runs only after every earlier task submitted
+// with the same key has finished, so tasks sharing a key execute one at a time
+// in submission order while tasks with different keys run concurrently.
+func (pool *GoroutinePool) SubmitKeyed(key string, task Task) error {
+	item := newTaskItem(task, nil)
+	item.key = key
+	return pool.enqueue(context.Background(), item)
+}
+
+// parkKeyed marks the key of t as in flight, or parks t behind the task already
+// running with that key and returns true
+func (pool *GoroutinePool) parkKeyed(t *taskItem) bool {
+	if t.key == "" {
+		return false
+	}
+	pool.cond.L.Lock()
+	defer pool.cond.L.Unlock()
+	if backlog, busy := pool.keyBacklog[t.key]; busy {
+		pool.keyBacklog[t.key] = append(backlog, t)
+		pool.parked++
+		return true
+	}
+	pool.keyBacklog[t.key] = nil
+	return false
+}
+
+// releaseKey is called once t is done with and hands its key to the next parked task
+func (pool *GoroutinePool) releaseKey(t *taskItem) {
+	if t.key == "" {
+		return
+	}
+	pool.cond.L.Lock()
+	backlog := pool.keyBacklog[t.key]
+	if len(backlog) == 0 {
+		delete(pool.keyBacklog, t.key)
+		pool.cond.L.Unlock()
+		return
+	}
+	pool.keyBacklog[t.key] = backlog[1:]
+	pool.parked--
+	pool.keyReady = append(pool.keyReady, backlog[0])
+	if pool.wake != nil {
+		pool.wake()
+	}
+	pool.cond.L.Unlock()
+	select {
+	case pool.keyReadyChan <- struct{}{}:
+	default:
+	}
+}
+
+// nextTask returns the next task for dispatch: keyed tasks released by their
+// predecessor first, then the task queue. While the pool is paused it waits
+// without taking anything off the queue. It returns false once the queue is
+// closed and no parked task is left, or once the pool is stopped.
+func (pool *GoroutinePool) nextTask() (*taskItem, bool) {
+	for {
+		pool.cond.L.Lock()
+		for pool.paused && !pool.stopped {
+			pool.cond.Wait()
+		}
+		if len(pool.keyReady) > 0 {
+			t := pool.keyReady[0]
+			pool.keyReady = pool.keyReady[1:]
+			pool.cond.L.Unlock()
+			return t, true
+		}
+		parked, stopped := pool.parked, pool.stopped
+		if pool.queueDrained {
+			pool.cond.L.Unlock()
+			if parked == 0 || stopped {
+				return nil, false
+			}
+			// 队列已关闭，只等待被阻塞的同key任务
+			select {
+			case <-pool.keyReadyChan:
+			case <-pool.stopCtx.Done():
+			}
+			continue
+		}
+		ctx, wake := pool.stopCtx, context.CancelFunc(nil)
+		if stopped {
+			// 强制释放时队列已关闭，取出剩余的任务直到队列为空，由 dispatch 丢弃
+			ctx = context.Background()
+		} else {
+			// 能被 releaseKey 和 Pause 打断
+			ctx, wake = context.WithCancel(pool.stopCtx)
+			pool.wake = wake
+		}
+		pool.cond.L.Unlock()
+
+		t, ok := pool.taskQueue.Pop(ctx)
+		// 在释放 ctx 之前判断 Pop 是否被打断
+		interrupted := ctx.Err() != nil
+		if wake != nil {
+			pool.cond.L.Lock()
+			pool.wake = nil
+			pool.cond.L.Unlock()
+			wake()
+		}
+		if !ok {
+			if !interrupted {
+				pool.queueDrained = true
+			}
+			continue
+		}
+		pool.notifySpace()
+		if t == nil || t.item == nil {
+			// 不是协程池放入的任务，违反了 TaskQueue 的约定。它没有被计数，忽略即可
+			continue
+		}
+		item := t.item
+		pool.dequeued(item)
+		if pool.parkKeyed(item) {
+			continue
+		}
+		return item, true
+	}
+}
+
+// discardParked drops the keyed tasks still waiting for their predecessor
+// after a forced release
+func (pool *GoroutinePool) discardParked() {
+	pool.cond.L.Lock()
+	var parked []*taskItem
+	for key, backlog := range pool.keyBacklog {
+		parked = append(parked, backlog...)
+		pool.keyBacklog[key] = nil
+	}
+	pool.parked = 0
+	for _, t := range parked {
+		if t.discard(ErrPoolReleased) {
+			pool.discarded++
+		}
+	}
+	pool.cond.L.Unlock()
+	for range parked {
+		pool.donePending()
+	}
+}
@@ -0,0 +1,121 @@
+package GoroutinePool
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubmitKeyed(t *testing.T) {
+	pool := NewGoroutinePool(4)
+	defer pool.Release()
+
+	var (
+		mu       sync.Mutex
+		order    = map[string][]int{}
+		inFlight = map[string]*atomic.Int32{"a": {}, "b": {}}
+	)
+	for i := 0; i < 50; i++ {
+		for _, key := range []string{"a", "b"} {
+			i, key := i, key
+			delay := time.Duration(rand.Intn(500)) * time.Microsecond
+			pool.SubmitKeyed(key, func() (interface{}, error) {
+				if n := inFlight[key].Add(1); n != 1 {
+					t.Errorf("%d tasks with key %q running at once", n, key)
+				}
+				time.Sleep(delay)
+				mu.Lock()
+				order[key] = append(order[key], i)
+				mu.Unlock()
+				inFlight[key].Add(-1)
+				return nil, nil
+			})
+		}
+	}
+	pool.Wait()
+
+	for key, seq := range order {
+		if len(seq) != 50 {
+			t.Fatalf("key %q: expected 50 tasks, got %d", key, len(seq))
+		}
+		for i, n := range seq {
+			if n != i {
+				t.Fatalf("key %q executed out of order: %v", key, seq)
+			}
+		}
+	}
+}
+
+func TestSubmitKeyedDistinctKeysRunConcurrently(t *testing.T) {
+	pool := NewGoroutinePool(4)
+	defer pool.Release()
+
+	var wg sync.WaitGroup
+	wg.Add(4)
+	for i := 0; i < 4; i++ {
+		pool.SubmitKeyed(fmt.Sprint(i), func() (interface{}, error) {
+			// every task waits for all the others, which deadlocks unless they run in parallel
+			wg.Done()
+			wg.Wait()
+			return nil, nil
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		pool.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("tasks with distinct keys did not run concurrently")
+	}
+}
+
+func TestReleaseDrainsKeyedTasks(t *testing.T) {
+	pool := NewGoroutinePool(2)
+
+	var executed atomic.Int32
+	for i := 0; i < 10; i++ {
+		pool.SubmitKeyed("k", func() (interface{}, error) {
+			time.Sleep(time.Millisecond)
+			executed.Add(1)
+			return nil, nil
+		})
+	}
+	pool.Release()
+	if n := executed.Load(); n != 10 {
+		t.Fatalf("expected all 10 keyed tasks to run before Release returned, got %d", n)
+	}
+}
+
+func TestForcedReleaseDiscardsParkedKeyedTasks(t *testing.T) {
+	pool := NewGoroutinePool(2)
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	defer close(unblock)
+	pool.SubmitKeyed("k", func() (interface{}, error) {
+		close(started)
+		<-unblock
+		return nil, nil
+	})
+	<-started
+	for i := 0; i < 3; i++ {
+		pool.SubmitKeyed("k", func() (interface{}, error) {
+			t.Error("parked keyed task ran after the release deadline")
+			return nil, nil
+		})
+	}
+
+	err := pool.ReleaseWithTimeout(20 * time.Millisecond)
+	if !errors.Is(err, ErrReleaseTimeout) || !strings.Contains(err.Error(), "3 tasks still queued") {
+		t.Fatalf("expected 3 discarded keyed tasks, got %v", err)
+	}
+}
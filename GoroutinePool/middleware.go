@@ -0,0 +1,47 @@
+package GoroutinePool
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// TaskMiddleware wraps the execution of a task, composing like HTTP middleware
+type TaskMiddleware func(next Task) Task
+
+// execute runs item through the middlewares and hooks of the pool. The Task
+// handed to the middlewares covers all attempts of item, so they and the after
+// hooks see the final outcome.
+func (w *Worker) execute(item *taskItem, pool *GoroutinePool) (interface{}, error) {
+	if len(pool.middlewares) == 0 && len(pool.beforeHooks) == 0 && len(pool.afterHooks) == 0 {
+		return w.executeTask(item, pool)
+	}
+	var run Task = func() (interface{}, error) {
+		return w.executeTask(item, pool)
+	}
+	// 先注册的中间件在最外层
+	for i := len(pool.middlewares) - 1; i >= 0; i-- {
+		run = pool.middlewares[i](run)
+	}
+	for _, hook := range pool.beforeHooks {
+		pool.runHook(hook)
+	}
+	begin := time.Now()
+	// 中间件的 panic 与任务的 panic 一样被恢复
+	result, err := w.runTask(run, pool)
+	duration := time.Since(begin)
+	for _, hook := range pool.afterHooks {
+		hook := hook
+		pool.runHook(func() { hook(result, err, duration) })
+	}
+	return result, err
+}
+
+// runHook calls hook, recovering a panic so that it cannot take the worker down
+func (pool *GoroutinePool) runHook(hook func()) {
+	defer func() {
+		if r := recover(); r != nil && pool.panicCallback != nil {
+			pool.panicCallback(r, debug.Stack())
+		}
+	}()
+	hook()
+}
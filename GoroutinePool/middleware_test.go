@@ -0,0 +1,120 @@
+package GoroutinePool
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskMiddlewareOrder(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		trace []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		trace = append(trace, s)
+		mu.Unlock()
+	}
+	tag := func(name string) TaskMiddleware {
+		return func(next Task) Task {
+			return func() (interface{}, error) {
+				record(name + " before")
+				result, err := next()
+				record(name + " after")
+				return result, err
+			}
+		}
+	}
+	pool := NewGoroutinePool(1,
+		WithTaskMiddleware(tag("outer")),
+		WithTaskMiddleware(tag("inner")),
+		WithBeforeTask(func() { record("hook before") }),
+		WithAfterTask(func(interface{}, error, time.Duration) { record("hook after") }),
+	)
+	defer pool.Release()
+
+	result, err := pool.SubmitWithResult(func() (interface{}, error) {
+		record("task")
+		return "ok", nil
+	}).Get()
+	if result != "ok" || err != nil {
+		t.Fatalf("got (%v, %v)", result, err)
+	}
+	pool.Wait()
+
+	want := []string{"hook before", "outer before", "inner before", "task", "inner after", "outer after", "hook after"}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(trace) != len(want) {
+		t.Fatalf("expected %v, got %v", want, trace)
+	}
+	for i := range want {
+		if trace[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, trace)
+		}
+	}
+}
+
+func TestAfterTaskHook(t *testing.T) {
+	type outcome struct {
+		result   interface{}
+		err      error
+		duration time.Duration
+	}
+	outcomes := make(chan outcome, 1)
+	pool := NewGoroutinePool(1,
+		WithRetryCount(2),
+		WithAfterTask(func(result interface{}, err error, duration time.Duration) {
+			outcomes <- outcome{result, err, duration}
+		}),
+	)
+	defer pool.Release()
+
+	// 钩子只在所有重试结束后调用一次
+	var attempts atomic.Int32
+	pool.Submit(func() (interface{}, error) {
+		time.Sleep(10 * time.Millisecond)
+		if attempts.Add(1) < 3 {
+			return nil, errors.New("not yet")
+		}
+		return "third", nil
+	})
+	got := <-outcomes
+	if got.result != "third" || got.err != nil {
+		t.Fatalf("expected the final outcome (third, nil), got (%v, %v)", got.result, got.err)
+	}
+	if got.duration < 30*time.Millisecond {
+		t.Fatalf("expected the duration to cover all 3 attempts, got %v", got.duration)
+	}
+}
+
+func TestHookPanicsAreRecovered(t *testing.T) {
+	var panics atomic.Int32
+	pool := NewGoroutinePool(1,
+		WithPanicCallback(func(interface{}, []byte) { panics.Add(1) }),
+		WithBeforeTask(func() { panic("before") }),
+		WithAfterTask(func(interface{}, error, time.Duration) { panic("after") }),
+		WithTaskMiddleware(func(next Task) Task {
+			return func() (interface{}, error) {
+				next()
+				panic("middleware")
+			}
+		}),
+	)
+	defer pool.Release()
+
+	for i := 0; i < 2; i++ {
+		_, err := pool.SubmitWithResult(func() (interface{}, error) { return nil, nil }).Get()
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) || panicErr.Recovered != "middleware" {
+			t.Fatalf("expected the middleware panic as the task error, got %v", err)
+		}
+	}
+	pool.Wait()
+	if n := panics.Load(); n != 6 {
+		t.Fatalf("expected 6 recovered panics, got %d", n)
+	}
+}
@@ -1,6 +1,7 @@
 package GoroutinePool
 
 import (
+	"math"
 	"sync"
 	"time"
 )
@@ -23,6 +24,75 @@ func WithMinWorkers(minWorkers int) Option {
 	}
 }
 
+// WithWorkerIdleTimeout makes the pool retire workers that have been idle for longer
+// than timeout, down to the minimum number of workers, instead of shrinking only
+// when the queue is empty and every worker is idle.
+func WithWorkerIdleTimeout(timeout time.Duration) Option {
+	return func(pool *GoroutinePool) {
+		pool.workerIdleTimeout = timeout
+	}
+}
+
+// WithAdjustInterval sets how often the adjuster grows and shrinks the pool,
+// one second by default. A non-positive interval is ignored.
+func WithAdjustInterval(interval time.Duration) Option {
+	return func(pool *GoroutinePool) {
+		if interval > 0 {
+			pool.adjustInterval = interval
+		}
+	}
+}
+
+// WithScaleUpThreshold makes the adjuster double the workers, up to the maximum,
+// once more than queuePerWorker tasks are queued for every worker. The default
+// is 0.75, zero grows the pool as soon as any task waits. A negative or
+// non-finite threshold is ignored.
+func WithScaleUpThreshold(queuePerWorker float64) Option {
+	return func(pool *GoroutinePool) {
+		if queuePerWorker >= 0 && !math.IsInf(queuePerWorker, 1) {
+			pool.scaleUpThreshold = queuePerWorker
+		}
+	}
+}
+
+// WithScaleDownPolicy sets how the adjuster shrinks a pool whose workers are all
+// idle, see ScaleDownPolicy. The default is ScaleDownHalf. An unknown policy is ignored.
+func WithScaleDownPolicy(policy ScaleDownPolicy) Option {
+	return func(pool *GoroutinePool) {
+		if policy >= ScaleDownHalf && policy <= ScaleDownNever {
+			pool.scaleDownPolicy = policy
+		}
+	}
+}
+
+// WithDispatchOrder sets which idle worker receives the next task, see
+// DispatchOrder. The default is DispatchLIFO. An unknown order is ignored.
+func WithDispatchOrder(order DispatchOrder) Option {
+	return func(pool *GoroutinePool) {
+		if order >= DispatchLIFO && order <= DispatchRoundRobin {
+			pool.dispatchOrder = order
+		}
+	}
+}
+
+// WithQueueTimeout makes dispatch discard tasks that have waited for longer than
+// timeout since they were submitted, instead of running them. Their futures fail
+// with ErrTaskExpired. A task submitted by SubmitWithContext or SubmitCtx expires
+// at the deadline of its context if that comes first.
+func WithQueueTimeout(timeout time.Duration) Option {
+	return func(pool *GoroutinePool) {
+		pool.queueTimeout = timeout
+	}
+}
+
+// WithExpiredCallback sets a callback invoked with every task that expires in
+// the queue, see WithQueueTimeout
+func WithExpiredCallback(callback func(Task)) Option {
+	return func(pool *GoroutinePool) {
+		pool.expiredCallback = callback
+	}
+}
+
 // WithTimeout sets the timeout for the pool
 func WithTimeout(timeout time.Duration) Option {
 	return func(pool *GoroutinePool) {
@@ -30,10 +100,51 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithResultCallback sets the result callback for the pool
+func WithResultCallback(callback func(interface{})) Option {
+	return func(pool *GoroutinePool) {
+		pool.resultCallback = callback
+	}
+}
+
 // WithResultCallBack sets the result callback for the pool
+//
+// Deprecated: use WithResultCallback.
 func WithResultCallBack(callback func(interface{})) Option {
+	return WithResultCallback(callback)
+}
+
+// WithCompletionCallback sets a callback that receives the final outcome of
+// every task, result and error together, once retries are exhausted. It is
+// called before, and independently of, the callbacks of WithErrCallback and
+// WithResultCallback, which keep their behavior when it is set.
+func WithCompletionCallback(callback func(result interface{}, err error)) Option {
 	return func(pool *GoroutinePool) {
-		pool.resultCallback = callback
+		pool.completionCallback = callback
+	}
+}
+
+// WithErrCallback sets the callback invoked with the error of a task that failed after all retries
+func WithErrCallback(callback func(error)) Option {
+	return func(pool *GoroutinePool) {
+		pool.errCallback = callback
+	}
+}
+
+// WithPanicCallback sets the callback invoked with the recovered value and stack of a panicking task
+func WithPanicCallback(callback func(recovered interface{}, stack []byte)) Option {
+	return func(pool *GoroutinePool) {
+		pool.panicCallback = callback
+	}
+}
+
+// WithResultChannel makes the pool send the outcome of every task on the channel
+// returned by Results. Results are dropped and counted in Stats.DroppedResults
+// when the consumer falls more than buffer results behind, so buffer must cover
+// the largest backlog the consumer is expected to build up.
+func WithResultChannel(buffer int) Option {
+	return func(pool *GoroutinePool) {
+		pool.results = &resultStream{ch: make(chan TaskResult, buffer)}
 	}
 }
 
@@ -44,9 +155,152 @@ func WithRetryCount(retryCount int) Option {
 	}
 }
 
+// WithBackoff sets the strategy used to delay retries of failed tasks.
+// A nil backoff is ignored.
+func WithBackoff(backoff Backoff) Option {
+	return func(pool *GoroutinePool) {
+		if backoff != nil {
+			pool.backoff = backoff
+		}
+	}
+}
+
+// WithRateLimit limits the pool to starting at most n tasks within any window of per.
+// Task starts are spaced evenly per/n apart; submission itself is not throttled.
+func WithRateLimit(n int, per time.Duration) Option {
+	return func(pool *GoroutinePool) {
+		pool.rateLimiter.set(n, per)
+	}
+}
+
 // WithTaskQueueSize sets the size of the task queue for the pool.
 func WithTaskQueueSize(size int) Option {
 	return func(pool *GoroutinePool) {
 		pool.taskQueueSize = size
 	}
 }
+
+// WithQueue makes the pool hold submitted tasks in q instead of the default
+// bounded queue. WithTaskQueueSize has no effect on it.
+// The queue is owned by the pool from then on and closed by Release.
+func WithQueue(q TaskQueue) Option {
+	return func(pool *GoroutinePool) {
+		pool.taskQueue = q
+	}
+}
+
+// WithRejectionPolicy sets what Submit and its variants do when the task queue
+// is full, see RejectionPolicy. The default is RejectBlock.
+func WithRejectionPolicy(policy RejectionPolicy) Option {
+	return func(pool *GoroutinePool) {
+		pool.rejectionPolicy = policy
+	}
+}
+
+// WithDropCallback sets a callback invoked with every task that RejectDropOldest
+// evicts from the queue
+func WithDropCallback(callback func(Task)) Option {
+	return func(pool *GoroutinePool) {
+		pool.dropCallback = callback
+	}
+}
+
+// WithSaturationCallback sets a callback that is invoked with true once
+// QueueUtilization rises to threshold or above, and with false once it drops
+// below threshold again. It fires on these transitions only and is evaluated
+// by the adjuster on every tick. Unbounded queues never saturate.
+func WithSaturationCallback(threshold float64, callback func(saturated bool)) Option {
+	return func(pool *GoroutinePool) {
+		pool.saturationThreshold = threshold
+		pool.saturationCallback = callback
+	}
+}
+
+// WithTaskMiddleware adds mw around the execution of every task. Middlewares
+// apply in registration order, so the first one registered is the outermost.
+// The Task passed to mw covers all retries of the task and panics are recovered
+// like those of the task itself.
+func WithTaskMiddleware(mw TaskMiddleware) Option {
+	return func(pool *GoroutinePool) {
+		pool.middlewares = append(pool.middlewares, mw)
+	}
+}
+
+// WithBeforeTask adds a hook invoked on the worker goroutine before every task.
+// A panicking hook is recovered and reported to the panic callback.
+func WithBeforeTask(hook func()) Option {
+	return func(pool *GoroutinePool) {
+		pool.beforeHooks = append(pool.beforeHooks, hook)
+	}
+}
+
+// WithAfterTask adds a hook invoked on the worker goroutine after every task
+// with its final outcome, once retries are exhausted, and how long it took.
+// A panicking hook is recovered and reported to the panic callback.
+func WithAfterTask(hook func(result interface{}, err error, duration time.Duration)) Option {
+	return func(pool *GoroutinePool) {
+		pool.afterHooks = append(pool.afterHooks, hook)
+	}
+}
+
+// WithWorkerInit sets a hook that creates the state of every worker when it is
+// started, including the workers added by the adjuster or Resize. Tasks of
+// SubmitCtx read the state of their worker with WorkerState. If it fails the
+// worker is not started and the error, wrapping ErrWorkerInit, goes to the error
+// callback. While the pool has no worker at all, tasks fail with that error
+// instead of waiting; the adjuster keeps retrying to start the minimum workers.
+func WithWorkerInit(init func() (workerState interface{}, err error)) Option {
+	return func(pool *GoroutinePool) {
+		pool.workerInit = init
+	}
+}
+
+// WithWorkerTeardown sets a hook that disposes of the state of a worker once
+// the worker is retired or the pool is released. It runs on the worker goroutine.
+func WithWorkerTeardown(teardown func(workerState interface{})) Option {
+	return func(pool *GoroutinePool) {
+		pool.workerTeardown = teardown
+	}
+}
+
+// WithProfile chooses how NewDefaultPool sizes the pool.
+// It has no effect on a pool created with an explicit maxWorkers.
+func WithProfile(profile Profile) Option {
+	return func(pool *GoroutinePool) {
+		pool.profile = profile
+	}
+}
+
+// WithGOMAXPROCS makes NewDefaultPool size the pool as if runtime.GOMAXPROCS
+// returned n. It is meant for tests.
+func WithGOMAXPROCS(n int) Option {
+	return func(pool *GoroutinePool) {
+		pool.gomaxprocs = n
+	}
+}
+
+// WithUnboundedQueue makes the pool hold submitted tasks in a queue that grows
+// as needed, so Submit never blocks on a full queue.
+func WithUnboundedQueue() Option {
+	return WithQueue(NewUnboundedQueue())
+}
+
+// TaskOption represents an option for a single task
+type TaskOption func(*taskItem)
+
+// TaskPriority sets the priority a TaskQueue reads through QueuedTask.Priority.
+// The built-in queues are FIFO and ignore it.
+func TaskPriority(priority int) TaskOption {
+	return func(item *taskItem) {
+		item.priority = priority
+	}
+}
+
+// TaskTimeout overrides the pool timeout for a single task.
+// A zero timeout runs the task without a timeout even if the pool has one.
+func TaskTimeout(timeout time.Duration) TaskOption {
+	return func(item *taskItem) {
+		item.timeout = timeout
+		item.hasTimeout = true
+	}
+}
@@ -0,0 +1,49 @@
+package GoroutinePool
+
+// DispatchOrder decides which idle worker dispatch hands the next task to
+type DispatchOrder int
+
+const (
+	// DispatchLIFO 优先使用最近归还的worker，轻负载时其余worker可以被缩容
+	DispatchLIFO DispatchOrder = iota
+	// DispatchFIFO 优先使用空闲最久的worker
+	DispatchFIFO
+	// DispatchRoundRobin 按worker的创建顺序轮流使用空闲的worker
+	DispatchRoundRobin
+)
+
+// popWorker takes an idle worker off the stack according to the dispatch order.
+// The caller must hold pool.lock and have checked that the stack is not empty.
+func (pool *GoroutinePool) popWorker() *Worker {
+	pos := len(pool.workerStack) - 1
+	switch pool.dispatchOrder {
+	case DispatchFIFO:
+		// 栈底的worker空闲时间最长
+		pos = 0
+	case DispatchRoundRobin:
+		pos = pool.nextInTurn()
+	}
+	worker := pool.workerStack[pos]
+	pool.workerStack = append(pool.workerStack[:pos], pool.workerStack[pos+1:]...)
+	pool.lastDispatched = worker.id
+	return worker
+}
+
+// nextInTurn returns the position in workerStack of the idle worker created
+// next after the one dispatched last, wrapping around to the oldest one.
+// The caller must hold pool.lock.
+func (pool *GoroutinePool) nextInTurn() int {
+	next, first := -1, 0
+	for i, w := range pool.workerStack {
+		if w.id < pool.workerStack[first].id {
+			first = i
+		}
+		if w.id > pool.lastDispatched && (next < 0 || w.id < pool.workerStack[next].id) {
+			next = i
+		}
+	}
+	if next < 0 {
+		return first
+	}
+	return next
+}
@@ -0,0 +1,90 @@
+package GoroutinePool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countPerWorker runs n quick tasks one after another on a pool of 4 workers,
+// each once every worker is idle again, and counts the tasks each worker ran
+func countPerWorker(t *testing.T, order DispatchOrder, n int) []int {
+	t.Helper()
+	var ids atomic.Int32
+	pool := NewGoroutinePool(4,
+		WithDispatchOrder(order),
+		WithWorkerInit(func() (interface{}, error) {
+			return int(ids.Add(1)) - 1, nil
+		}),
+	)
+	defer pool.Release()
+
+	counts := make([]int, 4)
+	for i := 0; i < n; i++ {
+		id, err := pool.SubmitCtx(context.Background(), func(ctx context.Context) (interface{}, error) {
+			return WorkerState(ctx), nil
+		}).Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[id.(int)]++
+		// 等待worker归还，使每次分发都面对相同的空闲worker
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			pool.lock.Lock()
+			idle := len(pool.workerStack) == len(pool.workers)
+			pool.lock.Unlock()
+			if idle {
+				break
+			}
+			time.Sleep(time.Microsecond)
+		}
+	}
+	return counts
+}
+
+func TestDispatchOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		order DispatchOrder
+		want  []int
+	}{
+		// 最近归还的worker执行所有任务
+		{"lifo", DispatchLIFO, []int{0, 0, 0, 40}},
+		{"fifo", DispatchFIFO, []int{10, 10, 10, 10}},
+		{"round robin", DispatchRoundRobin, []int{10, 10, 10, 10}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			counts := countPerWorker(t, tt.order, 40)
+			for i := range counts {
+				if counts[i] != tt.want[i] {
+					t.Fatalf("expected tasks per worker %v, got %v", tt.want, counts)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatchRoundRobinIgnoresIdleHistory(t *testing.T) {
+	pool := &GoroutinePool{dispatchOrder: DispatchRoundRobin}
+	for id := 1; id <= 4; id++ {
+		pool.workerStack = append(pool.workerStack, &Worker{id: id})
+	}
+	// worker 2 最近归还，仍然轮到它之后的 worker 3
+	pool.lastDispatched = 2
+	pool.workerStack = []*Worker{pool.workerStack[0], pool.workerStack[2], pool.workerStack[3], pool.workerStack[1]}
+
+	var got []int
+	for len(pool.workerStack) > 0 {
+		got = append(got, pool.popWorker().id)
+	}
+	want := []int{3, 4, 1, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected workers in turn %v, got %v", want, got)
+		}
+	}
+}
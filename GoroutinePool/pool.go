@@ -2,129 +2,679 @@ package GoroutinePool
 
 import (
 	"context"
-	"sort"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Pool interface {
-	// Submit 提交任务
-	Submit(task Task)
+	// Submit 提交任务，协程池已释放时返回 ErrPoolReleased
+	Submit(task Task) error
+	// SubmitWithResult 提交任务并返回可等待结果的 Future
+	SubmitWithResult(task Task, opts ...TaskOption) *Future
+	// SubmitWithOptions 使用任务级别的配置提交任务
+	SubmitWithOptions(task Task, opts ...TaskOption) error
+	// SubmitAll 批量提交任务，返回可单独等待的 Batch
+	SubmitAll(tasks []Task) *Batch
+	// SubmitOrdered 批量提交任务，返回与任务一一对应的 Future
+	SubmitOrdered(tasks []Task) []*Future
+	// NewGroup 创建可单独等待的任务组
+	NewGroup() *TaskGroup
+	// SubmitKeyed 提交任务，相同 key 的任务按提交顺序串行执行
+	SubmitKeyed(key string, task Task) error
+	// SubmitAfter 延迟 delay 后提交任务
+	SubmitAfter(task Task, delay time.Duration) *DelayedTask
+	// SubmitAt 在指定时间提交任务
+	SubmitAt(task Task, at time.Time) *DelayedTask
+	// SubmitCancellable 提交可取消的任务
+	SubmitCancellable(task TaskCtx, opts ...TaskOption) *TaskHandle
+	// SubmitCtx 提交接收 context 的任务，ctx 的取消会传递到任务中
+	SubmitCtx(ctx context.Context, task TaskCtx, opts ...TaskOption) *Future
+	// TrySubmit 非阻塞地提交任务，队列已满或协程池已释放时返回 false
+	TrySubmit(task Task) bool
+	// SubmitWithContext 提交任务，队列已满时最多阻塞到 ctx 结束
+	SubmitWithContext(ctx context.Context, task Task) error
 	// Wait 等待执行任务
 	Wait()
+	// WaitContext 等待执行任务，ctx 结束时提前返回
+	WaitContext(ctx context.Context) error
 	// Release 释放协程池
 	Release()
-	// GetRunning 获取运行中的协程数量
+	// ReleaseWithTimeout 释放协程池，最多等待 timeout 后强制结束
+	ReleaseWithTimeout(timeout time.Duration) error
+	// GetRunning 获取正在执行的任务数量
 	GetRunning() int
 	// GetWorkers 获取工作协程数量
 	GetWorkers() int
-	// GetTaskQueenSize 获取任务队列中的任务数量
+	// GetMinWorkers 获取最小工作协程数量
+	GetMinWorkers() int
+	// GetMaxWorkers 获取最大工作协程数量
+	GetMaxWorkers() int
+	// GetTimeout 获取任务的超时时间
+	GetTimeout() time.Duration
+	// GetRetryCount 获取任务的重试次数
+	GetRetryCount() int
+	// Config 获取协程池当前的配置
+	Config() Config
+	// GetTaskQueueLen 获取任务队列中等待的任务数量
+	GetTaskQueueLen() int
+	// GetTaskQueueCap 获取任务队列的容量
+	GetTaskQueueCap() int
+	// QueueUtilization 获取任务队列的使用率
+	QueueUtilization() float64
+	// GetTaskQueenSize 获取任务队列的容量
+	//
+	// Deprecated: use GetTaskQueueCap, or GetTaskQueueLen for the number of waiting tasks.
 	GetTaskQueenSize() int
+	// Stats 获取协程池的统计信息
+	Stats() Stats
+	// Results 获取任务结果的通道，需配合 WithResultChannel 使用
+	Results() <-chan TaskResult
+	// Resize 调整最小和最大工作协程数量
+	Resize(minWorkers, maxWorkers int) error
+	// SetRateLimit 调整任务开始执行的速率限制
+	SetRateLimit(n int, per time.Duration)
+	// Pause 暂停分发任务，已提交的任务继续排队
+	Pause()
+	// Resume 恢复分发任务
+	Resume()
+	// State 获取协程池的生命周期状态
+	State() PoolState
+	// IsReleased 协程池是否已开始释放
+	IsReleased() bool
 }
 
 type Task func() (interface{}, error)
 
+// TaskCtx is a task that observes cancellation through its context
+type TaskCtx func(ctx context.Context) (interface{}, error)
+
+// PoolState is the lifecycle state of a pool
+type PoolState int32
+
+const (
+	// StateRunning 正常接受和执行任务
+	StateRunning PoolState = iota
+	// StateDraining 释放中，不再接受任务，正在排空已提交的任务
+	StateDraining
+	// StateReleased 已释放完成
+	StateReleased
+)
+
+func (s PoolState) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateReleased:
+		return "released"
+	}
+	return fmt.Sprintf("PoolState(%d)", int32(s))
+}
+
+// ErrPoolReleased is returned when submitting to a pool that has been released
+var ErrPoolReleased = errors.New("goroutine pool has been released")
+
+// ErrTaskTimeout is returned for a task attempt that exceeded its timeout
+var ErrTaskTimeout = errors.New("task timeout")
+
+// ErrTaskCancelled is reported for a task that was cancelled before it started
+var ErrTaskCancelled = errors.New("task cancelled before it started")
+
+// ErrReleaseTimeout is returned by ReleaseWithTimeout when the pool could not drain in time
+var ErrReleaseTimeout = errors.New("goroutine pool release timed out")
+
+// taskItem is the unit carried through the task queues, pairing a Task with
+// the bookkeeping the pool needs once the task has been executed.
+type taskItem struct {
+	task   Task
+	future *Future
+	// onDone is invoked with the outcome of the task, or the reason it was discarded
+	onDone func(interface{}, error)
+	// timeout overrides the pool timeout when hasTimeout is set
+	timeout    time.Duration
+	hasTimeout bool
+	// cancel, when set, cancels the context handed to a TaskCtx
+	cancel context.CancelFunc
+	state  atomic.Int32
+	// key serializes the item with other items of the same key, see SubmitKeyed
+	key string
+	// taskCtx is the task of SubmitCtx, run with a context derived from ctx on
+	// every attempt. task then adapts it for callers expecting a plain Task.
+	taskCtx TaskCtx
+	ctx     context.Context
+	// queued is set while the item sits in the task queue, see withdraw
+	queued atomic.Bool
+	// expiry is when the item expires in the queue, zero if it never does
+	expiry time.Time
+	// priority is read by TaskQueues through QueuedTask.Priority
+	priority int
+}
+
+const (
+	taskPending int32 = iota
+	taskRunning
+	taskDone
+	taskCancelled
+)
+
+func newTaskItem(task Task, opts []TaskOption) *taskItem {
+	item := &taskItem{task: task}
+	for _, opt := range opts {
+		opt(item)
+	}
+	return item
+}
+
+// start marks the item as running.
+// It returns false if the item was cancelled before it could start.
+func (item *taskItem) start() bool {
+	return item.state.CompareAndSwap(taskPending, taskRunning)
+}
+
+// complete hands the outcome of the item to whoever is waiting for it
+func (item *taskItem) complete(result interface{}, err error) {
+	if item.future != nil {
+		item.future.complete(result, err)
+	}
+	if item.onDone != nil {
+		item.onDone(result, err)
+	}
+}
+
+// cancelled reports whether the item was discarded before it could start
+func (item *taskItem) cancelled() bool {
+	return item.state.Load() == taskCancelled
+}
+
+// finish marks the item as done and releases its context
+func (item *taskItem) finish() {
+	item.state.Store(taskDone)
+	if item.cancel != nil {
+		item.cancel()
+	}
+}
+
+// discard cancels an item that has not started yet, failing its future with err.
+// It returns false if the item has already started.
+func (item *taskItem) discard(err error) bool {
+	if !item.state.CompareAndSwap(taskPending, taskCancelled) {
+		return false
+	}
+	if item.cancel != nil {
+		item.cancel()
+	}
+	item.complete(nil, err)
+	return true
+}
+
 type GoroutinePool struct {
 	lock           sync.Locker
 	workers        []*Worker
-	workerStack    []int
+	workerStack    []*Worker
 	maxWorkers     int
 	minWorkers     int
-	taskQueue      chan Task
+	taskQueue      TaskQueue
 	taskQueueSize  int
 	retryCount     int
+	backoff        Backoff
 	cond           *sync.Cond
 	timeout        time.Duration
 	resultCallback func(interface{})
 	errCallback    func(error)
+	panicCallback  func(interface{}, []byte)
 	adjustInterval time.Duration
-	ctx            context.Context
-	cancel         context.CancelFunc
+	// scaleUpThreshold is the number of queued tasks per worker above which the
+	// adjuster grows the pool, scaleDownPolicy how it shrinks an idle pool
+	scaleUpThreshold float64
+	scaleDownPolicy  ScaleDownPolicy
+	ctx              context.Context
+	cancel           context.CancelFunc
+	// workerIdleTimeout retires workers idle for longer than it, zero disables it
+	workerIdleTimeout time.Duration
+	// dispatchOrder picks the idle worker for the next task. Workers are numbered
+	// by workerSeq in creation order, lastDispatched is the last one handed a task.
+	// Both are guarded by pool.lock.
+	dispatchOrder  DispatchOrder
+	workerSeq      int
+	lastDispatched int
+	// queueTimeout expires tasks that wait in the queue for longer, zero disables it
+	queueTimeout    time.Duration
+	expiredCallback func(Task)
+	// space is closed by dispatch once it takes a task, waking submitters
+	// blocked on a full queue. It is created on demand and guarded by spaceLock.
+	space     chan struct{}
+	spaceLock sync.Mutex
+	// state holds the PoolState, it leaves StateRunning once Release starts
+	state atomic.Int32
+	// releaseDone is closed once the pool reaches StateReleased
+	releaseDone chan struct{}
+	// dispatchDone is closed once dispatch has handed out every queued task
+	dispatchDone chan struct{}
+	// stopped makes dispatch discard the remaining tasks, guarded by cond.L.
+	// stopCtx is cancelled at the same time for waits outside of cond.
+	stopped   bool
+	stopCtx   context.Context
+	stop      context.CancelFunc
+	discarded int
+	stats     poolStats
+	// withdrawn counts cancelled tasks still sitting in taskQueue
+	withdrawn atomic.Int64
+	// running counts the tasks being executed, from their first attempt until
+	// their final outcome is known
+	running atomic.Int64
+	// paused stops dispatch from handing out tasks, guarded by cond.L
+	paused bool
+	// pending counts submitted tasks that have not finished yet. idle is closed
+	// whenever it drops to zero and replaced once new tasks arrive, both guarded by waitLock.
+	pending  int64
+	idle     chan struct{}
+	waitLock sync.Mutex
+	// rateLimiter throttles how fast dispatch starts tasks
+	rateLimiter rateLimiter
+	// delayed holds the tasks of SubmitAfter that have not entered the queue yet
+	delayed     map[*DelayedTask]struct{}
+	delayedLock sync.Mutex
+	// results streams task outcomes when WithResultChannel is set
+	results *resultStream
+	// keyBacklog holds the keys in flight and the keyed tasks parked behind them,
+	// keyReady the parked tasks whose predecessor has finished. Both are guarded
+	// by cond.L, and keyReadyChan wakes dispatch when keyReady grows.
+	keyBacklog   map[string][]*taskItem
+	keyReady     []*taskItem
+	keyReadyChan chan struct{}
+	parked       int
+	// queueDrained is set by dispatch once taskQueue is closed and empty
+	queueDrained bool
+	// wake interrupts a Pop of dispatch when a parked keyed task becomes ready
+	// or the pool is paused, guarded by cond.L
+	wake context.CancelFunc
+	// rejectionPolicy decides what enqueue does on a full queue, dropCallback
+	// receives the tasks evicted by RejectDropOldest
+	rejectionPolicy RejectionPolicy
+	dropCallback    func(Task)
+	// saturationCallback is told when QueueUtilization crosses saturationThreshold.
+	// saturated is the last state reported, only touched by the adjuster.
+	saturationThreshold float64
+	saturationCallback  func(saturated bool)
+	saturated           bool
+	// middlewares wrap every task execution in registration order, beforeHooks
+	// and afterHooks run around it on the worker goroutine
+	middlewares []TaskMiddleware
+	beforeHooks []func()
+	afterHooks  []func(result interface{}, err error, duration time.Duration)
+	// completionCallback receives the outcome of every task, before the split callbacks
+	completionCallback func(result interface{}, err error)
+	// workerInit and workerTeardown create and dispose of the state of every
+	// worker. workerGroup tracks the worker goroutines so Release can wait for
+	// their teardown.
+	workerInit     func() (interface{}, error)
+	workerTeardown func(interface{})
+	workerGroup    sync.WaitGroup
+	// initErr is the init failure that left the pool without any worker,
+	// guarded by pool.lock
+	initErr error
+	// profile and gomaxprocs size a pool created without maxWorkers, see NewDefaultPool
+	profile    Profile
+	gomaxprocs int
 }
 
+// NewGoroutinePool creates a pool running up to maxWorkers workers.
+// A non-positive maxWorkers sizes the pool for this machine, see NewDefaultPool.
 func NewGoroutinePool(maxWorkers int, options ...Option) *GoroutinePool {
 	ctx, cancel := context.WithCancel(context.Background())
 	pool := &GoroutinePool{
-		lock:           new(sync.Mutex),
-		maxWorkers:     maxWorkers,
-		minWorkers:     maxWorkers,
-		workers:        nil,
-		workerStack:    nil,
-		taskQueue:      nil,
-		taskQueueSize:  1e6,
-		retryCount:     0,
-		timeout:        0,
-		cond:           nil,
-		adjustInterval: 1 * time.Second,
-		ctx:            ctx,
-		cancel:         cancel,
+		lock:             new(sync.Mutex),
+		maxWorkers:       maxWorkers,
+		minWorkers:       maxWorkers,
+		workers:          nil,
+		workerStack:      nil,
+		taskQueue:        nil,
+		taskQueueSize:    1e6,
+		retryCount:       0,
+		backoff:          NoBackoff(),
+		timeout:          0,
+		cond:             nil,
+		adjustInterval:   1 * time.Second,
+		scaleUpThreshold: defaultScaleUpThreshold,
+		ctx:              ctx,
+		cancel:           cancel,
+		dispatchDone:     make(chan struct{}),
+		releaseDone:      make(chan struct{}),
+		delayed:          make(map[*DelayedTask]struct{}),
+		keyBacklog:       make(map[string][]*taskItem),
+		keyReadyChan:     make(chan struct{}, 1),
 	}
 	// apply options
 	for _, opt := range options {
 		opt(pool)
 	}
-	pool.taskQueue = make(chan Task, pool.taskQueueSize)
-	pool.workers = make([]*Worker, pool.minWorkers)
-	pool.workerStack = make([]int, pool.minWorkers)
+	if pool.maxWorkers <= 0 {
+		pool.autoSize()
+	}
+	if pool.taskQueue == nil {
+		pool.taskQueue = NewBoundedQueue(pool.taskQueueSize)
+	}
+	pool.stopCtx, pool.stop = context.WithCancel(context.Background())
+	pool.workers = make([]*Worker, 0, pool.minWorkers)
+	pool.workerStack = make([]*Worker, 0, pool.minWorkers)
 
 	if pool.cond == nil {
 		pool.cond = sync.NewCond(pool.lock)
 	}
+	pool.idle = make(chan struct{})
+	close(pool.idle)
 	// create workers
-	for i := 0; i < pool.minWorkers; i++ {
-		worker := newWorker()
-		pool.workers[i] = worker
-		pool.workerStack[i] = i
-		// 真正去执行任务
-		worker.start(pool, i)
-	}
+	pool.reportError(pool.growWorkers(pool.minWorkers))
 	// process requests
 	go pool.adjustWorkers()
 	go pool.dispatch()
 	return pool
 }
 
-func (pool *GoroutinePool) Submit(task Task) {
-	pool.taskQueue <- task
+// Submit submits a task, blocking while the task queue is full unless another
+// RejectionPolicy is set. It returns ErrPoolReleased once the pool has been released.
+func (pool *GoroutinePool) Submit(task Task) error {
+	return pool.enqueue(context.Background(), &taskItem{task: task})
 }
 
-// Wait waits for all tasks to be dispatched and completed
-func (pool *GoroutinePool) Wait() {
-	for {
-		pool.lock.Lock()
-		workerStackLen := len(pool.workerStack)
-		pool.lock.Unlock()
+// SubmitWithResult submits a task and returns a Future that is completed with
+// the task's outcome once retries and timeout have been applied.
+// If the pool has been released the Future completes with ErrPoolReleased.
+func (pool *GoroutinePool) SubmitWithResult(task Task, opts ...TaskOption) *Future {
+	item := newTaskItem(task, opts)
+	item.future = newFuture()
+	if err := pool.enqueue(context.Background(), item); err != nil {
+		item.future.complete(nil, err)
+	}
+	return item.future
+}
 
-		if len(pool.taskQueue) == 0 && workerStackLen == len(pool.workers) {
-			break
+// SubmitWithOptions submits a task whose behavior is adjusted by opts,
+// blocking while the task queue is full.
+func (pool *GoroutinePool) SubmitWithOptions(task Task, opts ...TaskOption) error {
+	return pool.enqueue(context.Background(), newTaskItem(task, opts))
+}
+
+// TrySubmit submits a task without blocking.
+// It returns false when the task queue is full or the pool has been released.
+func (pool *GoroutinePool) TrySubmit(task Task) bool {
+	return pool.push(context.Background(), &taskItem{task: task}, false) == nil
+}
+
+// SubmitWithContext submits a task, blocking while the task queue is full.
+// It returns ctx.Err() if ctx is done first and ErrPoolReleased once the pool is released.
+func (pool *GoroutinePool) SubmitWithContext(ctx context.Context, task Task) error {
+	return pool.enqueue(ctx, &taskItem{task: task})
+}
+
+// SubmitCtx submits a task that receives a context on every attempt. The context
+// derives from ctx and is cancelled when ctx is, when ReleaseWithTimeout gives up
+// on the running tasks, or when the attempt exceeds the task timeout. If ctx is done before the task
+// starts, the task is withdrawn and its Future fails with ctx.Err().
+func (pool *GoroutinePool) SubmitCtx(ctx context.Context, task TaskCtx, opts ...TaskOption) *Future {
+	item := newTaskItem(func() (interface{}, error) {
+		return task(ctx)
+	}, opts)
+	item.taskCtx = task
+	item.ctx = ctx
+	item.future = newFuture()
+	// 任务开始前 ctx 结束则撤回任务
+	stop := context.AfterFunc(ctx, func() {
+		if item.discard(ctx.Err()) {
+			pool.withdraw(item)
+		}
+	})
+	item.cancel = func() { stop() }
+	if err := pool.enqueue(ctx, item); err != nil {
+		item.discard(err)
+	}
+	return item.future
+}
+
+// enqueue pushes item to the task queue unless the pool has been released,
+// waiting for dispatch to make room while the queue is full.
+// Release cancels pool.ctx before closing the queue, so a blocked sender gives up.
+func (pool *GoroutinePool) enqueue(ctx context.Context, item *taskItem) error {
+	return pool.push(ctx, item, true)
+}
+
+// push pushes item to the task queue. On a full queue it applies the rejection
+// policy if block is set, and fails with ErrQueueFull otherwise.
+func (pool *GoroutinePool) push(ctx context.Context, item *taskItem, block bool) error {
+	if pool.IsReleased() {
+		return ErrPoolReleased
+	}
+	// 先计数再入队，避免任务在计数前就已执行完
+	pool.addPending()
+	pool.setExpiry(ctx, item)
+	// 入队前标记，否则可能在标记前就被 dispatch 取走
+	item.queued.Store(true)
+	task := &QueuedTask{item: item}
+	err := pool.taskQueue.Push(task)
+	if err == ErrQueueFull && block {
+		// 队列已满，按拒绝策略处理
+		switch {
+		case pool.rejectionPolicy == RejectError:
+			pool.stats.rejected.Add(1)
+		case pool.rejectionPolicy == RejectDropOldest:
+			err = pool.pushDroppingOldest(task)
+		case pool.rejectionPolicy == RejectCallerRuns && item.key == "" && !pool.isPaused():
+			item.queued.Store(false)
+			pool.stats.submitted.Add(1)
+			pool.runInCaller(item)
+			return nil
+		default:
+			err = pool.pushWaiting(ctx, task)
+		}
+	}
+	if err != nil {
+		item.queued.Store(false)
+		pool.donePending()
+		if err == ErrQueueClosed {
+			return ErrPoolReleased
+		}
+		return err
+	}
+	pool.stats.submitted.Add(1)
+	return nil
+}
+
+// pushWaiting pushes task onto the full task queue once dispatch makes room
+func (pool *GoroutinePool) pushWaiting(ctx context.Context, task *QueuedTask) error {
+	for {
+		// 先取通知再重试，避免错过两者之间的出队
+		space := pool.waitSpace()
+		if err := pool.taskQueue.Push(task); err != ErrQueueFull {
+			return err
+		}
+		select {
+		case <-space:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-pool.ctx.Done():
+			return ErrPoolReleased
 		}
+	}
+}
+
+// waitSpace returns a channel that is closed once dispatch takes the next task
+func (pool *GoroutinePool) waitSpace() <-chan struct{} {
+	pool.spaceLock.Lock()
+	defer pool.spaceLock.Unlock()
+	if pool.space == nil {
+		pool.space = make(chan struct{})
+	}
+	return pool.space
+}
 
-		time.Sleep(50 * time.Millisecond)
+// notifySpace wakes the submitters waiting in waitSpace
+func (pool *GoroutinePool) notifySpace() {
+	pool.spaceLock.Lock()
+	if pool.space != nil {
+		close(pool.space)
+		pool.space = nil
 	}
+	pool.spaceLock.Unlock()
 }
 
+func (pool *GoroutinePool) addPending() {
+	pool.waitLock.Lock()
+	pool.pending++
+	if pool.pending == 1 {
+		pool.idle = make(chan struct{})
+	}
+	pool.waitLock.Unlock()
+}
+
+// donePending marks a submitted task as finished and wakes Wait once none are left
+func (pool *GoroutinePool) donePending() {
+	pool.waitLock.Lock()
+	pool.pending--
+	if pool.pending == 0 {
+		close(pool.idle)
+	}
+	pool.waitLock.Unlock()
+}
+
+// Wait waits for all submitted tasks to be dispatched and completed,
+// including tasks submitted by running tasks.
+// It also returns once the pool has been released.
+func (pool *GoroutinePool) Wait() {
+	_ = pool.WaitContext(context.Background())
+}
+
+// WaitContext is like Wait but returns ctx.Err() if ctx is done before the pool drains.
+// It returns ErrPoolReleased if the pool is released while tasks are still
+// outstanding, which happens when ReleaseWithTimeout gives up on busy workers.
+func (pool *GoroutinePool) WaitContext(ctx context.Context) error {
+	pool.waitLock.Lock()
+	idle := pool.idle
+	pool.waitLock.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-pool.releaseDone:
+		select {
+		case <-idle:
+			return nil
+		default:
+			return ErrPoolReleased
+		}
+	}
+}
+
+// State returns the lifecycle state of the pool
+func (pool *GoroutinePool) State() PoolState {
+	return PoolState(pool.state.Load())
+}
+
+// IsReleased reports whether Release has been called, so the pool is either
+// draining or fully released and rejects new tasks
+func (pool *GoroutinePool) IsReleased() bool {
+	return pool.State() != StateRunning
+}
+
+// Release stops accepting tasks and waits for queued and running tasks to complete
 func (pool *GoroutinePool) Release() {
+	_ = pool.ReleaseWithTimeout(0)
+}
+
+// ReleaseWithTimeout stops accepting tasks and waits up to timeout for queued and
+// running tasks to complete. When the deadline fires the tasks still queued are
+// discarded, their futures fail with ErrPoolReleased, and an error wrapping
+// ErrReleaseTimeout reports what was left. A non-positive timeout waits indefinitely.
+// A paused pool is resumed so that its queue can drain.
+// A call made while another release is in progress waits for it to finish, up
+// to its own timeout, and returns nil once the pool has been released.
+func (pool *GoroutinePool) ReleaseWithTimeout(timeout time.Duration) error {
 	// 不再接受后续的请求
-	close(pool.taskQueue)
+	if !pool.state.CompareAndSwap(int32(StateRunning), int32(StateDraining)) {
+		return pool.waitReleased(timeout)
+	}
+	pool.dropDelayed()
 	pool.cancel()
-	pool.cond.L.Lock()
-	// 等待现行所有任务执行完成
-	for len(pool.workerStack) != pool.minWorkers {
-		pool.cond.Wait()
+	pool.taskQueue.Close()
+	// 暂停中的协程池同样需要排空队列
+	pool.Resume()
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		// 等待已入队的任务全部分发出去
+		<-pool.dispatchDone
+		pool.cond.L.Lock()
+		// 等待现行所有任务执行完成
+		for len(pool.workerStack) != len(pool.workers) && !pool.stopped {
+			pool.cond.Wait()
+		}
+		pool.cond.L.Unlock()
+	}()
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
 	}
-	pool.cond.L.Unlock()
+
+	var err error
+	select {
+	case <-drained:
+	case <-deadline:
+		pool.cond.L.Lock()
+		pool.stopped = true
+		pool.stop()
+		busy := len(pool.workers) - len(pool.workerStack)
+		pool.cond.L.Unlock()
+		pool.cond.Broadcast()
+		<-drained
+		err = fmt.Errorf("%w: %d tasks still queued, %d workers still busy", ErrReleaseTimeout, pool.discarded, busy)
+	}
+
+	pool.cond.L.Lock()
 	for _, worker := range pool.workers {
 		close(worker.taskQueue)
 	}
 	pool.workers = nil
 	pool.workerStack = nil
+	pool.cond.L.Unlock()
+	if err == nil {
+		// 所有worker均已空闲，等待它们退出并执行清理钩子
+		pool.workerGroup.Wait()
+	}
+	if pool.results != nil {
+		pool.results.close()
+	}
+	pool.state.Store(int32(StateReleased))
+	close(pool.releaseDone)
+	return err
+}
+
+// waitReleased waits up to timeout for the release started by another call
+func (pool *GoroutinePool) waitReleased(timeout time.Duration) error {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	select {
+	case <-pool.releaseDone:
+		return nil
+	case <-deadline:
+		return fmt.Errorf("%w: still waiting for the release in progress", ErrReleaseTimeout)
+	}
 }
 
-// GetRunning 获取运行中的协程数量
+// GetRunning 获取正在执行的任务数量
 func (pool *GoroutinePool) GetRunning() int {
-	pool.lock.Lock()
-	defer pool.lock.Unlock()
-	return len(pool.workers) - len(pool.workerStack)
+	return int(pool.running.Load())
 }
 
 // GetWorkers 获取工作协程数量
@@ -134,24 +684,209 @@ func (pool *GoroutinePool) GetWorkers() int {
 	return len(pool.workers)
 }
 
-// GetTaskQueenSize 获取任务队列中的任务数量
+// GetTaskQueueLen 获取任务队列中等待的任务数量，不含已取消的任务
+func (pool *GoroutinePool) GetTaskQueueLen() int {
+	return pool.queueLen()
+}
+
+// withdraw is called after a cancelled item has been discarded. Cancelled items
+// cannot be taken out of the TaskQueue, so while item is still queued it is
+// counted in withdrawn until dispatch pops and skips it.
+func (pool *GoroutinePool) withdraw(item *taskItem) {
+	if item.queued.CompareAndSwap(true, false) {
+		pool.withdrawn.Add(1)
+	}
+}
+
+// dequeued is called by dispatch for every item received from the task queue
+func (pool *GoroutinePool) dequeued(item *taskItem) {
+	if !item.queued.CompareAndSwap(true, false) {
+		pool.withdrawn.Add(-1)
+	}
+}
+
+// queueLen returns the number of queued tasks that have not been cancelled
+func (pool *GoroutinePool) queueLen() int {
+	return max(pool.taskQueue.Len()-int(pool.withdrawn.Load()), 0)
+}
+
+// GetTaskQueueCap 获取任务队列的容量，无界队列返回 -1
+func (pool *GoroutinePool) GetTaskQueueCap() int {
+	if q, ok := pool.taskQueue.(capacity); ok {
+		return q.Cap()
+	}
+	return -1
+}
+
+// GetTaskQueenSize 获取任务队列的容量
+//
+// Deprecated: use GetTaskQueueCap, or GetTaskQueueLen for the number of waiting tasks.
 func (pool *GoroutinePool) GetTaskQueenSize() int {
+	return pool.GetTaskQueueCap()
+}
+
+// Resize changes the worker bounds of the pool at runtime.
+// Missing workers up to the new minimum are started immediately, while workers
+// above the new maximum are retired by the adjuster once they are idle.
+func (pool *GoroutinePool) Resize(minWorkers, maxWorkers int) error {
+	if minWorkers < 1 || minWorkers > maxWorkers {
+		return fmt.Errorf("invalid worker bounds: min %d, max %d", minWorkers, maxWorkers)
+	}
 	pool.lock.Lock()
-	defer pool.lock.Unlock()
-	return pool.taskQueueSize
+	if pool.IsReleased() {
+		pool.lock.Unlock()
+		return ErrPoolReleased
+	}
+	pool.minWorkers = minWorkers
+	pool.maxWorkers = maxWorkers
+	missing := minWorkers - len(pool.workers)
+	pool.lock.Unlock()
+	// 唤醒等待空闲worker的任务
+	pool.cond.Broadcast()
+	if missing > 0 {
+		pool.reportError(pool.growWorkers(missing))
+	}
+	return nil
+}
+
+// Pause stops the pool from starting queued tasks while running tasks finish.
+// Submissions keep queueing up until Resume is called. Pausing twice has no extra effect.
+func (pool *GoroutinePool) Pause() {
+	pool.cond.L.Lock()
+	pool.paused = true
+	// 让 dispatch 放弃正在等待的 Pop，暂停期间任务留在队列中
+	if pool.wake != nil {
+		pool.wake()
+	}
+	pool.cond.L.Unlock()
+}
+
+// isPaused reports whether Pause is in effect
+func (pool *GoroutinePool) isPaused() bool {
+	pool.cond.L.Lock()
+	defer pool.cond.L.Unlock()
+	return pool.paused
+}
+
+// Resume lets the pool start queued tasks again after Pause
+func (pool *GoroutinePool) Resume() {
+	pool.cond.L.Lock()
+	pool.paused = false
+	pool.cond.L.Unlock()
+	pool.cond.Broadcast()
 }
 
-func (pool *GoroutinePool) popWorker() int {
+// growWorkers starts up to n idle workers, never going above maxWorkers.
+// The init hook runs without holding pool.lock; workers whose init fails are
+// not started and their errors are returned for the caller to report.
+// The caller must not hold pool.lock.
+func (pool *GoroutinePool) growWorkers(n int) error {
+	var (
+		workers []*Worker
+		errs    []error
+	)
+	for i := 0; i < n; i++ {
+		worker := newWorker()
+		if pool.workerInit != nil {
+			state, err := pool.workerInit()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: %w", ErrWorkerInit, err))
+				continue
+			}
+			worker.state = state
+		}
+		workers = append(workers, worker)
+	}
+	err := errors.Join(errs...)
+
 	pool.lock.Lock()
-	workerIndex := pool.workerStack[len(pool.workerStack)-1]
-	pool.workerStack = pool.workerStack[:len(pool.workerStack)-1]
+	var extra []*Worker
+	if pool.ctx.Err() != nil {
+		// 协程池正在释放，不能再启动新的worker
+		extra = workers
+	} else {
+		// 初始化期间可能已有其他调用者补充了worker
+		room := max(pool.maxWorkers-len(pool.workers), 0)
+		if len(workers) > room {
+			workers, extra = workers[:room], workers[room:]
+		}
+		pool.addWorkers(workers)
+	}
+	// 一个worker都没有时，dispatch 用初始化的错误结束任务，而不是一直等待
+	if len(pool.workers) == 0 {
+		pool.initErr = err
+	} else {
+		pool.initErr = nil
+	}
 	pool.lock.Unlock()
-	return workerIndex
+	pool.cond.Broadcast()
+
+	if pool.workerTeardown != nil {
+		for _, worker := range extra {
+			worker := worker
+			pool.runHook(func() { pool.workerTeardown(worker.state) })
+		}
+	}
+	return err
+}
+
+// addWorkers starts the given idle workers, the caller must hold pool.lock
+func (pool *GoroutinePool) addWorkers(workers []*Worker) {
+	for _, worker := range workers {
+		pool.workerSeq++
+		worker.id = pool.workerSeq
+		worker.lastActive = time.Now()
+		pool.workers = append(pool.workers, worker)
+		pool.workerStack = append(pool.workerStack, worker)
+		// 真正去执行任务
+		pool.workerGroup.Add(1)
+		worker.start(pool)
+	}
+}
+
+// retireWorker stops the idle worker found at position pos of workerStack.
+// Only idle workers are retired, so no task can be sent to it afterwards.
+// The caller must hold pool.lock.
+func (pool *GoroutinePool) retireWorker(pos int) {
+	worker := pool.workerStack[pos]
+	pool.workerStack = append(pool.workerStack[:pos], pool.workerStack[pos+1:]...)
+	last := len(pool.workers) - 1
+	for i, w := range pool.workers {
+		if w == worker {
+			pool.workers[i] = pool.workers[last]
+			break
+		}
+	}
+	pool.workers[last] = nil
+	pool.workers = pool.workers[:last]
+	close(worker.taskQueue)
+}
+
+// retireIdleWorkers retires the workers that have been idle for longer than
+// workerIdleTimeout, as well as idle workers above maxWorkers, never going
+// below minWorkers. The caller must hold pool.lock.
+// It may retire the last idle worker while others are busy: dispatch takes its
+// worker off the stack in the same critical section in which it found the stack
+// non-empty, so a worker it has committed to is never on the stack here.
+func (pool *GoroutinePool) retireIdleWorkers() bool {
+	retired := false
+	now := time.Now()
+	// 栈底的worker空闲时间最长
+	for len(pool.workerStack) > 0 && len(pool.workers) > pool.minWorkers {
+		worker := pool.workerStack[0]
+		if len(pool.workers) <= pool.maxWorkers && now.Sub(worker.lastActive) < pool.workerIdleTimeout {
+			break
+		}
+		pool.retireWorker(0)
+		retired = true
+	}
+	return retired
 }
 
-func (pool *GoroutinePool) pushWorker(workerIndex int) {
+func (pool *GoroutinePool) pushWorker(worker *Worker) {
 	pool.lock.Lock()
-	pool.workerStack = append(pool.workerStack, workerIndex)
+	worker.lastActive = time.Now()
+	pool.workerStack = append(pool.workerStack, worker)
 	pool.lock.Unlock()
 	// 加入/归还了新的worker，唤醒阻塞的任务
 	pool.cond.Signal()
@@ -161,37 +896,56 @@ func (pool *GoroutinePool) adjustWorkers() {
 	ticker := time.NewTicker(pool.adjustInterval)
 	defer ticker.Stop()
 
-	var adjustFlag bool
+	var (
+		adjustFlag bool
+		grow       int
+	)
 
 	for {
-		adjustFlag = false
+		adjustFlag, grow = false, 0
 		select {
 		case <-ticker.C:
 			pool.cond.L.Lock()
-			if len(pool.taskQueue) > len(pool.workers)*3/4 && len(pool.workers) < pool.maxWorkers {
+			if pool.ctx.Err() != nil {
+				// 协程池正在释放，不能再启动新的worker
+				pool.cond.L.Unlock()
+				return
+			}
+			if len(pool.workers) < pool.minWorkers {
+				// worker初始化失败时补足最小数量
+				grow = pool.minWorkers - len(pool.workers)
+			} else if pool.shouldScaleUp() {
 				// 扩容
-				adjustFlag = true
 				// double the number of workers until it reaches the maximum
-				newWorkerNum := min(len(pool.workers)*2, pool.maxWorkers) - len(pool.workers)
-				for i := 0; i < newWorkerNum; i++ {
-					worker := newWorker()
-					pool.workers = append(pool.workers, worker)
-					pool.workerStack = append(pool.workerStack, len(pool.workers)-1)
-					worker.start(pool, len(pool.workers)-1)
-				}
-			} else if len(pool.taskQueue) == 0 && len(pool.workerStack) == len(pool.workers) && len(pool.workers) > pool.minWorkers {
+				grow = min(len(pool.workers)*2, pool.maxWorkers) - len(pool.workers)
+			} else if len(pool.workers) > pool.maxWorkers && len(pool.workerStack) > 0 {
+				// Resize 降低了上限，不论其他worker是否忙碌，停止超出上限的空闲worker
 				adjustFlag = true
-				removeWorkerNum := (len(pool.workers) - pool.minWorkers + 1) / 2
-				// sort the workIndex before removing workers
-				sort.Ints(pool.workerStack)
-				pool.workers = pool.workers[:len(pool.workers)-removeWorkerNum]
-				pool.workerStack = pool.workerStack[:len(pool.workerStack)-removeWorkerNum]
+				for len(pool.workerStack) > 0 && len(pool.workers) > pool.maxWorkers {
+					pool.retireWorker(0)
+				}
+			} else if pool.workerIdleTimeout > 0 {
+				// 缩容空闲超时的worker
+				adjustFlag = pool.retireIdleWorkers()
+			} else if len(pool.workerStack) == len(pool.workers) &&
+				(len(pool.workers) > pool.maxWorkers || pool.queueLen() == 0 && len(pool.workers) > pool.minWorkers) {
+				removeWorkerNum := pool.scaleDownCount()
+				adjustFlag = removeWorkerNum > 0
+				// 所有worker均空闲，逐个停止而不是直接截断，避免泄漏协程
+				for i := 0; i < removeWorkerNum; i++ {
+					pool.retireWorker(0)
+				}
 			}
 			pool.cond.L.Unlock()
 			if adjustFlag {
 				// 唤醒所有的任务
 				pool.cond.Broadcast()
 			}
+			if grow > 0 {
+				// 在锁外初始化新的worker
+				pool.reportError(pool.growWorkers(grow))
+			}
+			pool.checkSaturation()
 		case <-pool.ctx.Done():
 			return
 		}
@@ -199,14 +953,62 @@ func (pool *GoroutinePool) adjustWorkers() {
 }
 
 func (pool *GoroutinePool) dispatch() {
-	for t := range pool.taskQueue {
+	defer close(pool.dispatchDone)
+	for {
+		t, ok := pool.nextTask()
+		if !ok {
+			break
+		}
+		// 已取消或已过期的任务不占用限流名额和worker
+		if t.cancelled() || pool.expire(t) {
+			pool.releaseKey(t)
+			pool.donePending()
+			continue
+		}
 		pool.cond.L.Lock()
-		// 没有可用的worker，等待
-		for len(pool.workerStack) == 0 {
+		// 没有可用的worker或已暂停，等待。worker全部初始化失败时不再等待
+		for (len(pool.workerStack) == 0 && pool.initErr == nil || pool.paused) && !pool.stopped {
 			pool.cond.Wait()
 		}
+		if !pool.stopped && len(pool.workerStack) == 0 {
+			// 没有任何worker能启动，任务以初始化的错误结束
+			err := pool.initErr
+			pool.cond.L.Unlock()
+			t.discard(err)
+			pool.releaseKey(t)
+			pool.donePending()
+			continue
+		}
+		if !pool.stopped {
+			// 在同一临界区内取出worker，避免被缩容的协程抢先停止
+			worker := pool.popWorker()
+			pool.cond.L.Unlock()
+			// 拿到worker后再限流，等待worker的时间不能占用限流名额。强制释放时不再等待
+			pool.rateLimiter.wait(pool.stopCtx.Done())
+			pool.cond.L.Lock()
+			if !pool.stopped {
+				pool.cond.L.Unlock()
+				// 等待worker期间过期的任务同样丢弃
+				if pool.expire(t) {
+					pool.pushWorker(worker)
+					pool.releaseKey(t)
+					pool.donePending()
+					continue
+				}
+				worker.taskQueue <- t
+				continue
+			}
+			// 限流期间被强制释放，归还worker
+			pool.workerStack = append(pool.workerStack, worker)
+		}
+		// 强制释放，丢弃剩余的任务
+		if t.discard(ErrPoolReleased) {
+			pool.discarded++
+		}
 		pool.cond.L.Unlock()
-		workerIndex := pool.popWorker()
-		pool.workers[workerIndex].taskQueue <- t
+		pool.releaseKey(t)
+		pool.donePending()
 	}
+	// 强制释放时丢弃仍在等待同key任务的任务
+	pool.discardParked()
 }
@@ -0,0 +1,1106 @@
+package GoroutinePool
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockWorker occupies the only worker of pool until the returned function is called
+func blockWorker(pool *GoroutinePool) func() {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	pool.Submit(func() (interface{}, error) {
+		close(started)
+		<-unblock
+		return nil, nil
+	})
+	<-started
+	return func() { close(unblock) }
+}
+
+func TestSubmitWithResult(t *testing.T) {
+	pool := NewGoroutinePool(4)
+	defer pool.Release()
+
+	futures := make([]*Future, 100)
+	for i := range futures {
+		i := i
+		futures[i] = pool.SubmitWithResult(func() (interface{}, error) {
+			return i, nil
+		})
+	}
+	for i, future := range futures {
+		result, err := future.Get()
+		if err != nil {
+			t.Fatalf("task %d: unexpected error %v", i, err)
+		}
+		if result != i {
+			t.Fatalf("task %d: got result %v", i, result)
+		}
+	}
+}
+
+func TestFutureCachesOutcome(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	defer pool.Release()
+
+	taskErr := errors.New("task failed")
+	future := pool.SubmitWithResult(func() (interface{}, error) {
+		return "partial", taskErr
+	})
+
+	<-future.Done()
+	for i := 0; i < 3; i++ {
+		result, err := future.Get()
+		if result != "partial" || err != taskErr {
+			t.Fatalf("Get #%d returned (%v, %v)", i, result, err)
+		}
+	}
+}
+
+func TestFutureGetWithContext(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	defer pool.Release()
+
+	unblock := make(chan struct{})
+	future := pool.SubmitWithResult(func() (interface{}, error) {
+		<-unblock
+		return "done", nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := future.GetWithContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	close(unblock)
+	result, err := future.GetWithContext(context.Background())
+	if result != "done" || err != nil {
+		t.Fatalf("got (%v, %v)", result, err)
+	}
+}
+
+func TestTrySubmit(t *testing.T) {
+	pool := NewGoroutinePool(1, WithTaskQueueSize(1))
+
+	unblock := blockWorker(pool)
+
+	// the only worker is blocked, so the dispatcher holds at most one task and
+	// the queue another one before it reports full
+	var wg sync.WaitGroup
+	accepted := 0
+	for ; accepted < 3; accepted++ {
+		wg.Add(1)
+		if !pool.TrySubmit(func() (interface{}, error) {
+			wg.Done()
+			return nil, nil
+		}) {
+			wg.Done()
+			break
+		}
+	}
+	if accepted == 0 || accepted > 2 {
+		t.Fatalf("expected the queue to fill after 1 or 2 tasks, accepted %d", accepted)
+	}
+
+	unblock()
+	wg.Wait()
+	if !pool.TrySubmit(func() (interface{}, error) { return nil, nil }) {
+		t.Fatal("TrySubmit on an idle pool returned false")
+	}
+	pool.Release()
+
+	if pool.TrySubmit(func() (interface{}, error) { return nil, nil }) {
+		t.Fatal("TrySubmit after Release returned true")
+	}
+}
+
+func TestSubmitWithContext(t *testing.T) {
+	pool := NewGoroutinePool(1, WithTaskQueueSize(1))
+
+	unblock := blockWorker(pool)
+
+	// saturate the dispatcher and the queue
+	var wg sync.WaitGroup
+	for {
+		wg.Add(1)
+		if !pool.TrySubmit(func() (interface{}, error) {
+			wg.Done()
+			return nil, nil
+		}) {
+			wg.Done()
+			break
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := pool.SubmitWithContext(ctx, func() (interface{}, error) {
+		t.Error("task submitted with a canceled context was executed")
+		return nil, nil
+	})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	unblock()
+	wg.Wait()
+	pool.Release()
+
+	err = pool.SubmitWithContext(context.Background(), func() (interface{}, error) { return nil, nil })
+	if err != ErrPoolReleased {
+		t.Fatalf("expected ErrPoolReleased, got %v", err)
+	}
+}
+
+func TestSubmitAfterRelease(t *testing.T) {
+	pool := NewGoroutinePool(4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := pool.Submit(func() (interface{}, error) { return nil, nil })
+				if err == ErrPoolReleased {
+					return
+				}
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	pool.Release()
+	wg.Wait()
+
+	if err := pool.Submit(func() (interface{}, error) { return nil, nil }); err != ErrPoolReleased {
+		t.Fatalf("expected ErrPoolReleased, got %v", err)
+	}
+	if _, err := pool.SubmitWithResult(func() (interface{}, error) { return nil, nil }).Get(); err != ErrPoolReleased {
+		t.Fatalf("expected future to fail with ErrPoolReleased, got %v", err)
+	}
+}
+
+func TestReleaseWithTimeoutDrains(t *testing.T) {
+	pool := NewGoroutinePool(2)
+
+	var executed atomic.Int32
+	for i := 0; i < 10; i++ {
+		pool.Submit(func() (interface{}, error) {
+			time.Sleep(5 * time.Millisecond)
+			executed.Add(1)
+			return nil, nil
+		})
+	}
+
+	if err := pool.ReleaseWithTimeout(time.Second); err != nil {
+		t.Fatalf("expected a clean release, got %v", err)
+	}
+	if n := executed.Load(); n != 10 {
+		t.Fatalf("expected 10 tasks to run before release returned, got %d", n)
+	}
+}
+
+func TestReleaseWithTimeoutDeadline(t *testing.T) {
+	pool := NewGoroutinePool(1)
+
+	unblock := blockWorker(pool)
+	defer unblock()
+
+	futures := make([]*Future, 3)
+	for i := range futures {
+		futures[i] = pool.SubmitWithResult(func() (interface{}, error) {
+			t.Error("queued task ran after the release deadline")
+			return nil, nil
+		})
+	}
+
+	err := pool.ReleaseWithTimeout(20 * time.Millisecond)
+	if !errors.Is(err, ErrReleaseTimeout) {
+		t.Fatalf("expected ErrReleaseTimeout, got %v", err)
+	}
+	if want := "3 tasks still queued, 1 workers still busy"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected %q in %q", want, err)
+	}
+	for _, future := range futures {
+		if _, err := future.Get(); err != ErrPoolReleased {
+			t.Fatalf("expected discarded task to fail with ErrPoolReleased, got %v", err)
+		}
+	}
+}
+
+func TestPoolState(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	if s := pool.State(); s != StateRunning || pool.IsReleased() {
+		t.Fatalf("expected a new pool to be running, got %v", s)
+	}
+
+	unblock := blockWorker(pool)
+
+	released := make(chan struct{})
+	go func() {
+		pool.Release()
+		close(released)
+	}()
+	for pool.State() == StateRunning {
+		time.Sleep(time.Millisecond)
+	}
+	if s := pool.State(); s != StateDraining || !pool.IsReleased() {
+		t.Fatalf("expected the pool to be draining, got %v", s)
+	}
+	if err := pool.Submit(func() (interface{}, error) { return nil, nil }); err != ErrPoolReleased {
+		t.Fatalf("expected ErrPoolReleased while draining, got %v", err)
+	}
+	if pool.TrySubmit(func() (interface{}, error) { return nil, nil }) {
+		t.Fatal("expected TrySubmit to fail while draining")
+	}
+
+	unblock()
+	<-released
+	if s := pool.State(); s != StateReleased || !pool.IsReleased() {
+		t.Fatalf("expected the pool to be released, got %v", s)
+	}
+	pool.Wait()
+}
+
+func TestConcurrentRelease(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	unblock := blockWorker(pool)
+
+	go pool.Release()
+	for pool.State() == StateRunning {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 第二次释放等待正在进行的释放完成
+	if err := pool.ReleaseWithTimeout(10 * time.Millisecond); !errors.Is(err, ErrReleaseTimeout) {
+		t.Fatalf("expected ErrReleaseTimeout while the first release drains, got %v", err)
+	}
+	released := make(chan struct{})
+	go func() {
+		pool.Release()
+		close(released)
+	}()
+	select {
+	case <-released:
+		t.Fatalf("a second Release returned while the pool was %v", pool.State())
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unblock()
+	<-released
+	if s := pool.State(); s != StateReleased {
+		t.Fatalf("expected the pool to be released once Release returned, got %v", s)
+	}
+}
+
+func TestPoolStateReleaseWithTimeout(t *testing.T) {
+	pool := NewGoroutinePool(1)
+
+	unblock := blockWorker(pool)
+	defer unblock()
+
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- pool.WaitContext(context.Background())
+	}()
+
+	if err := pool.ReleaseWithTimeout(10 * time.Millisecond); !errors.Is(err, ErrReleaseTimeout) {
+		t.Fatalf("expected ErrReleaseTimeout, got %v", err)
+	}
+	if s := pool.State(); s != StateReleased {
+		t.Fatalf("expected the pool to be released after the deadline, got %v", s)
+	}
+	// 被放弃的任务仍在运行，WaitContext 不应一直阻塞
+	select {
+	case err := <-waitErr:
+		if err != ErrPoolReleased {
+			t.Fatalf("expected ErrPoolReleased from WaitContext, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitContext hung on a released pool")
+	}
+	if err := pool.ReleaseWithTimeout(time.Second); err != nil {
+		t.Fatalf("expected a repeated release to be a no-op, got %v", err)
+	}
+}
+
+func TestSubmitCtxTimeout(t *testing.T) {
+	pool := NewGoroutinePool(1, WithTimeout(20*time.Millisecond))
+	defer pool.Release()
+
+	exited := make(chan struct{})
+	_, err := pool.SubmitCtx(context.Background(), func(ctx context.Context) (interface{}, error) {
+		defer close(exited)
+		for ctx.Err() == nil {
+			time.Sleep(time.Millisecond)
+		}
+		return nil, ctx.Err()
+	}).Get()
+	if err != ErrTaskTimeout {
+		t.Fatalf("expected ErrTaskTimeout, got %v", err)
+	}
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("the task kept running after its timeout")
+	}
+}
+
+func TestSubmitCtxCancellation(t *testing.T) {
+	pool := NewGoroutinePool(1)
+	defer pool.Release()
+
+	loop := func(started chan struct{}) TaskCtx {
+		return func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+	}
+
+	// 提交时的 ctx 被取消，传递到正在执行的任务
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	future := pool.SubmitCtx(ctx, loop(started))
+	<-started
+	cancel()
+	if _, err := future.Get(); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// 任务开始前 ctx 被取消，任务被撤回
+	unblock := blockWorker(pool)
+	ctx, cancel = context.WithCancel(context.Background())
+	future = pool.SubmitCtx(ctx, func(ctx context.Context) (interface{}, error) {
+		t.Error("task ran after its context was cancelled")
+		return nil, nil
+	})
+	cancel()
+	// 撤回在 ctx 的 AfterFunc 中异步进行
+	<-future.Done()
+	unblock()
+	if _, err := future.Get(); err != context.Canceled {
+		t.Fatalf("expected context.Canceled for a withdrawn task, got %v", err)
+	}
+}
+
+func TestSubmitCtxRelease(t *testing.T) {
+	pool := NewGoroutinePool(1)
+
+	started := make(chan struct{})
+	future := pool.SubmitCtx(context.Background(), func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return "stopped", nil
+	})
+	<-started
+
+	// 超时后强制释放，取消正在执行的任务
+	released := make(chan error)
+	go func() {
+		released <- pool.ReleaseWithTimeout(10 * time.Millisecond)
+	}()
+	select {
+	case err := <-released:
+		if !errors.Is(err, ErrReleaseTimeout) {
+			t.Fatalf("expected ErrReleaseTimeout, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a forced release did not cancel the running task")
+	}
+	if result, err := future.Get(); result != "stopped" || err != nil {
+		t.Fatalf("got (%v, %v)", result, err)
+	}
+}
+
+func TestSubmitCtxGracefulRelease(t *testing.T) {
+	pool := NewGoroutinePool(1)
+
+	futures := make([]*Future, 3)
+	for i := range futures {
+		futures[i] = pool.SubmitCtx(context.Background(), func(ctx context.Context) (interface{}, error) {
+			time.Sleep(10 * time.Millisecond)
+			return "done", ctx.Err()
+		})
+	}
+	// 正常释放会等待排队的任务执行完，它们的ctx不会被取消
+	pool.Release()
+	for i, future := range futures {
+		if result, err := future.Get(); result != "done" || err != nil {
+			t.Fatalf("task %d: got (%v, %v)", i, result, err)
+		}
+	}
+}
+
+func TestTaskTimeoutOverride(t *testing.T) {
+	slowTask := func() (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "slow", nil
+	}
+
+	tests := []struct {
+		name        string
+		poolTimeout time.Duration
+		taskTimeout time.Duration
+		wantErr     error
+	}{
+		{"shorter than the pool timeout", time.Second, 10 * time.Millisecond, ErrTaskTimeout},
+		{"longer than the pool timeout", 10 * time.Millisecond, time.Second, nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewGoroutinePool(1, WithTimeout(tt.poolTimeout))
+			defer pool.Release()
+			_, err := pool.SubmitWithResult(slowTask, TaskTimeout(tt.taskTimeout)).Get()
+			if err != tt.wantErr {
+				t.Fatalf("expected the %v per-task timeout to override the %v pool timeout, got %v",
+					tt.taskTimeout, tt.poolTimeout, err)
+			}
+		})
+	}
+}
+
+func TestTaskTimeoutZero(t *testing.T) {
+	pool := NewGoroutinePool(1, WithTimeout(10*time.Millisecond))
+	defer pool.Release()
+	result, err := pool.SubmitWithResult(func() (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "slow", nil
+	}, TaskTimeout(0)).Get()
+	if result != "slow" || err != nil {
+		t.Fatalf("expected a zero per-task timeout to disable the pool timeout, got (%v, %v)", result, err)
+	}
+}
+
+func TestTimeoutTaskError(t *testing.T) {
+	taskErr := errors.New("task failed")
+	pool := NewGoroutinePool(1, WithTimeout(time.Minute))
+	defer pool.Release()
+
+	for i := 0; i < 20; i++ {
+		f := pool.SubmitWithResult(func() (interface{}, error) {
+			return nil, taskErr
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := f.GetWithContext(ctx)
+		cancel()
+		if !errors.Is(err, taskErr) {
+			t.Fatalf("expected the task error under a generous timeout, got %v", err)
+		}
+	}
+
+	result, err := pool.SubmitWithResult(func() (interface{}, error) {
+		return "ok", nil
+	}).Get()
+	if result != "ok" || err != nil {
+		t.Fatalf("expected (ok, nil), got (%v, %v)", result, err)
+	}
+}
+
+func TestRecoverTaskPanic(t *testing.T) {
+	var (
+		recovered interface{}
+		stack     []byte
+	)
+	pool := NewGoroutinePool(1, WithPanicCallback(func(r interface{}, s []byte) {
+		recovered, stack = r, s
+	}))
+	defer pool.Release()
+
+	_, err := pool.SubmitWithResult(func() (interface{}, error) {
+		panic("boom")
+	}).Get()
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a *PanicError, got %v", err)
+	}
+	if panicErr.Recovered != "boom" || !strings.Contains(string(panicErr.Stack), "TestRecoverTaskPanic") {
+		t.Fatalf("unexpected panic error %v", panicErr)
+	}
+	if recovered != "boom" || len(stack) == 0 {
+		t.Fatalf("panic callback got (%v, %d bytes of stack)", recovered, len(stack))
+	}
+
+	// the single worker must have survived and returned itself to the pool
+	result, err := pool.SubmitWithResult(func() (interface{}, error) {
+		return "ok", nil
+	}).Get()
+	if result != "ok" || err != nil {
+		t.Fatalf("task after a panic returned (%v, %v)", result, err)
+	}
+}
+
+func TestGetTaskQueueLen(t *testing.T) {
+	pool := NewGoroutinePool(1, WithTaskQueueSize(64))
+
+	unblock := blockWorker(pool)
+
+	// the dispatcher takes one task off the queue while it waits for the busy worker
+	const queued = 10
+	for i := 0; i < queued+1; i++ {
+		pool.Submit(func() (interface{}, error) { return nil, nil })
+	}
+	deadline := time.Now().Add(time.Second)
+	for pool.GetTaskQueueLen() != queued && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := pool.GetTaskQueueLen(); n != queued {
+		t.Fatalf("expected %d queued tasks, got %d", queued, n)
+	}
+	if n := pool.GetTaskQueueCap(); n != 64 {
+		t.Fatalf("expected capacity 64, got %d", n)
+	}
+
+	unblock()
+	pool.Release()
+	if n := pool.GetTaskQueueLen(); n != 0 {
+		t.Fatalf("expected an empty queue after Release, got %d", n)
+	}
+}
+
+func TestErrCallback(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		errs     []error
+		results  []interface{}
+		attempts atomic.Int32
+	)
+	pool := NewGoroutinePool(1,
+		WithRetryCount(2),
+		WithErrCallback(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}),
+		WithResultCallback(func(result interface{}) {
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}),
+	)
+
+	taskErr := errors.New("always fails")
+	pool.SubmitWithResult(func() (interface{}, error) {
+		attempts.Add(1)
+		return nil, taskErr
+	}).Get()
+	pool.SubmitWithResult(func() (interface{}, error) {
+		return "ok", nil
+	}).Get()
+	pool.Release()
+
+	if n := attempts.Load(); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+	if len(errs) != 1 || errs[0] != taskErr {
+		t.Fatalf("expected the error callback to see %v once, got %v", taskErr, errs)
+	}
+	if len(results) != 1 || results[0] != "ok" {
+		t.Fatalf("expected the result callback to see only the successful task, got %v", results)
+	}
+}
+
+func TestCompletionCallback(t *testing.T) {
+	type outcome struct {
+		result interface{}
+		err    error
+	}
+	var (
+		mu       sync.Mutex
+		outcomes []outcome
+		order    []string
+	)
+	pool := NewGoroutinePool(1,
+		WithCompletionCallback(func(result interface{}, err error) {
+			mu.Lock()
+			outcomes = append(outcomes, outcome{result, err})
+			order = append(order, "completion")
+			mu.Unlock()
+		}),
+		WithErrCallback(func(error) {
+			mu.Lock()
+			order = append(order, "err")
+			mu.Unlock()
+		}),
+		WithResultCallback(func(interface{}) {
+			mu.Lock()
+			order = append(order, "result")
+			mu.Unlock()
+		}),
+	)
+
+	taskErr := errors.New("partial failure")
+	pool.SubmitWithResult(func() (interface{}, error) {
+		return "partial", taskErr
+	}).Get()
+	pool.SubmitWithResult(func() (interface{}, error) {
+		return "ok", nil
+	}).Get()
+	pool.Release()
+
+	want := []outcome{{"partial", taskErr}, {"ok", nil}}
+	if len(outcomes) != len(want) || outcomes[0] != want[0] || outcomes[1] != want[1] {
+		t.Fatalf("expected the completion callback to see %v, got %v", want, outcomes)
+	}
+	if got := strings.Join(order, ","); got != "completion,err,completion,result" {
+		t.Fatalf("unexpected callback order %s", got)
+	}
+}
+
+func TestRetryOutcome(t *testing.T) {
+	taskErr := errors.New("attempt failed")
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		attempt  func(n int32) (interface{}, error)
+		result   interface{}
+		err      error
+		attempts int32
+	}{
+		{
+			name: "success on attempt 2",
+			attempt: func(n int32) (interface{}, error) {
+				if n < 2 {
+					return nil, taskErr
+				}
+				return n, nil
+			},
+			result:   int32(2),
+			attempts: 2,
+		},
+		{
+			name: "always fail",
+			attempt: func(n int32) (interface{}, error) {
+				return nil, taskErr
+			},
+			err:      taskErr,
+			attempts: 3,
+		},
+		{
+			name:    "always time out",
+			timeout: 10 * time.Millisecond,
+			attempt: func(n int32) (interface{}, error) {
+				time.Sleep(50 * time.Millisecond)
+				return n, nil
+			},
+			err:      ErrTaskTimeout,
+			attempts: 3,
+		},
+		{
+			name:    "timeout then success",
+			timeout: 20 * time.Millisecond,
+			attempt: func(n int32) (interface{}, error) {
+				if n == 1 {
+					time.Sleep(100 * time.Millisecond)
+				}
+				return n, nil
+			},
+			result:   int32(2),
+			attempts: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				attempts  atomic.Int32
+				callbacks atomic.Int32
+			)
+			pool := NewGoroutinePool(1,
+				WithRetryCount(2),
+				WithTimeout(tt.timeout),
+				WithResultCallback(func(interface{}) { callbacks.Add(1) }),
+				WithErrCallback(func(error) {}),
+			)
+			result, err := pool.SubmitWithResult(func() (interface{}, error) {
+				return tt.attempt(attempts.Add(1))
+			}).Get()
+			pool.Release()
+
+			if result != tt.result || !errors.Is(err, tt.err) || (tt.err == nil) != (err == nil) {
+				t.Fatalf("expected (%v, %v), got (%v, %v)", tt.result, tt.err, result, err)
+			}
+			if tt.err != nil && callbacks.Load() != 0 {
+				t.Fatal("a failed task must not reach the result callback")
+			}
+			if got := pool.Stats().RetriedAttempts; got != int64(tt.attempts-1) {
+				t.Fatalf("expected %d retried attempts, got %d", tt.attempts-1, got)
+			}
+			// 超时的尝试可能仍在后台运行，只检查至少的次数
+			if n := attempts.Load(); n < tt.attempts {
+				t.Fatalf("expected at least %d attempts, got %d", tt.attempts, n)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	pool := NewGoroutinePool(2)
+	defer pool.Release()
+
+	if err := pool.Resize(5, 3); err == nil {
+		t.Fatal("expected an error for min > max")
+	}
+	if err := pool.Resize(0, 3); err == nil {
+		t.Fatal("expected an error for min < 1")
+	}
+
+	if err := pool.Resize(5, 8); err != nil {
+		t.Fatal(err)
+	}
+	if n := pool.GetWorkers(); n != 5 {
+		t.Fatalf("expected 5 workers right after Resize, got %d", n)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		pool.Submit(func() (interface{}, error) {
+			wg.Done()
+			return nil, nil
+		})
+	}
+	wg.Wait()
+}
+
+func TestResizeShrinkUnderLoad(t *testing.T) {
+	// 队列很小，提交者被阻塞，负载保持稳定
+	pool := NewGoroutinePool(8, WithTaskQueueSize(4), WithAdjustInterval(time.Millisecond))
+	defer pool.Release()
+
+	// 持续的负载使得worker从不同时空闲
+	stop := make(chan struct{})
+	loaded := make(chan struct{})
+	go func() {
+		defer close(loaded)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			pool.Submit(func() (interface{}, error) {
+				time.Sleep(time.Millisecond)
+				return nil, nil
+			})
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-loaded
+	}()
+
+	if err := pool.Resize(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for pool.GetWorkers() > 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := pool.GetWorkers(); n > 2 {
+		t.Fatalf("expected the pool to shrink to the new maximum of 2 under load, got %d workers", n)
+	}
+}
+
+func TestWorkerIdleTimeout(t *testing.T) {
+	pool := NewGoroutinePool(8, WithMinWorkers(1), WithWorkerIdleTimeout(20*time.Millisecond))
+	defer pool.Release()
+
+	if err := pool.Resize(8, 8); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Resize(1, 8); err != nil {
+		t.Fatal(err)
+	}
+	futures := make([]*Future, 32)
+	for i := range futures {
+		futures[i] = pool.SubmitWithResult(func() (interface{}, error) {
+			time.Sleep(time.Millisecond)
+			return nil, nil
+		})
+	}
+	for _, future := range futures {
+		future.Get()
+	}
+
+	// the adjuster runs every second
+	deadline := time.Now().Add(3 * time.Second)
+	for pool.GetWorkers() > 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := pool.GetWorkers(); n != 1 {
+		t.Fatalf("expected idle workers to be retired down to 1, got %d", n)
+	}
+
+	result, err := pool.SubmitWithResult(func() (interface{}, error) { return "ok", nil }).Get()
+	if result != "ok" || err != nil {
+		t.Fatalf("pool failed to run a task after scaling down: (%v, %v)", result, err)
+	}
+}
+
+func TestScaleDownStopsWorkers(t *testing.T) {
+	pool := NewGoroutinePool(2)
+	defer pool.Release()
+	baseline := runtime.NumGoroutine()
+
+	if err := pool.Resize(16, 16); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Resize(2, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// the adjuster runs every second
+	deadline := time.Now().Add(3 * time.Second)
+	for (pool.GetWorkers() > 2 || runtime.NumGoroutine() > baseline) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := pool.GetWorkers(); n != 2 {
+		t.Fatalf("expected the pool to shrink to 2 workers, got %d", n)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		t.Fatalf("retired workers leaked goroutines: %d running, %d before growing", n, baseline)
+	}
+}
+
+func TestIdleRetirementUnderLoad(t *testing.T) {
+	pool := NewGoroutinePool(8,
+		WithMinWorkers(1),
+		WithWorkerIdleTimeout(time.Nanosecond),
+		// 让调整协程尽可能频繁地缩容空闲的worker
+		WithAdjustInterval(time.Microsecond),
+	)
+	defer pool.Release()
+
+	var executed atomic.Int32
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if err := pool.Resize(8, 8); err != nil {
+			t.Fatal(err)
+		}
+		futures := make([]*Future, 16)
+		for i := range futures {
+			futures[i] = pool.SubmitWithResult(func() (interface{}, error) {
+				executed.Add(1)
+				return nil, nil
+			})
+		}
+		if err := pool.Resize(1, 8); err != nil {
+			t.Fatal(err)
+		}
+		for _, future := range futures {
+			if _, err := future.Get(); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		}
+	}
+	if executed.Load() == 0 {
+		t.Fatal("no task was executed")
+	}
+}
+
+func TestGetRunning(t *testing.T) {
+	const n = 5
+	pool := NewGoroutinePool(8, WithMinWorkers(1))
+	defer pool.Release()
+
+	var started sync.WaitGroup
+	started.Add(n)
+	unblock := make(chan struct{})
+	futures := make([]*Future, n)
+	for i := range futures {
+		futures[i] = pool.SubmitWithResult(func() (interface{}, error) {
+			started.Done()
+			<-unblock
+			return nil, nil
+		})
+	}
+	// 扩容中的worker和空闲worker都不计入
+	pool.Resize(8, 8)
+	started.Wait()
+	if got := pool.GetRunning(); got != n {
+		t.Fatalf("expected %d running tasks, got %d", n, got)
+	}
+	if got := pool.Stats().BusyWorkers; got != n {
+		t.Fatalf("expected %d busy workers, got %d", n, got)
+	}
+
+	close(unblock)
+	for _, future := range futures {
+		future.Get()
+	}
+	if got := pool.GetRunning(); got != 0 {
+		t.Fatalf("expected no running tasks once all futures completed, got %d", got)
+	}
+}
+
+func TestResizeUnderLoad(t *testing.T) {
+	pool := NewGoroutinePool(8, WithMinWorkers(1))
+	defer pool.Release()
+
+	var (
+		wg       sync.WaitGroup
+		executed atomic.Int32
+	)
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				pool.SubmitWithResult(func() (interface{}, error) {
+					executed.Add(1)
+					return nil, nil
+				})
+			}
+		}()
+	}
+
+	// grow and shrink as fast as possible while tasks are flowing,
+	// retiring idle workers the same way the adjuster does
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if err := pool.Resize(8, 8); err != nil {
+			t.Fatal(err)
+		}
+		if err := pool.Resize(1, 2); err != nil {
+			t.Fatal(err)
+		}
+		pool.lock.Lock()
+		pool.retireIdleWorkers()
+		pool.lock.Unlock()
+		pool.cond.Broadcast()
+	}
+	close(stop)
+	wg.Wait()
+
+	result, err := pool.SubmitWithResult(func() (interface{}, error) { return "ok", nil }).Get()
+	if result != "ok" || err != nil {
+		t.Fatalf("pool failed to run a task after resizing: (%v, %v)", result, err)
+	}
+	if executed.Load() == 0 {
+		t.Fatal("no task was executed while resizing")
+	}
+}
+
+func TestWait(t *testing.T) {
+	pool := NewGoroutinePool(4)
+	defer pool.Release()
+
+	var executed atomic.Int32
+	var spawn func(depth int) Task
+	spawn = func(depth int) Task {
+		return func() (interface{}, error) {
+			time.Sleep(time.Millisecond)
+			if depth > 0 {
+				// tasks submitted by a running task are waited for as well
+				pool.Submit(spawn(depth - 1))
+				pool.Submit(spawn(depth - 1))
+			}
+			executed.Add(1)
+			return nil, nil
+		}
+	}
+	pool.Submit(spawn(4))
+	pool.Wait()
+
+	if n := executed.Load(); n != 31 {
+		t.Fatalf("expected 31 tasks to have run when Wait returned, got %d", n)
+	}
+
+	// Wait on an idle pool returns immediately
+	pool.Wait()
+}
+
+func TestPauseResume(t *testing.T) {
+	pool := NewGoroutinePool(4)
+	defer pool.Release()
+
+	pool.Pause()
+	pool.Pause()
+
+	var executed atomic.Int32
+	for i := 0; i < 10; i++ {
+		pool.Submit(func() (interface{}, error) {
+			executed.Add(1)
+			return nil, nil
+		})
+	}
+	time.Sleep(30 * time.Millisecond)
+	if n := executed.Load(); n != 0 {
+		t.Fatalf("%d tasks ran while the pool was paused", n)
+	}
+	// dispatch 不会在暂停期间取走任务
+	if n := pool.GetTaskQueueLen(); n != 10 {
+		t.Fatalf("expected all 10 tasks to stay queued while paused, got %d", n)
+	}
+	if n := pool.GetRunning(); n != 0 {
+		t.Fatalf("expected no running workers while paused, got %d", n)
+	}
+
+	pool.Resume()
+	pool.Wait()
+	if n := executed.Load(); n != 10 {
+		t.Fatalf("expected 10 tasks after Resume, got %d", n)
+	}
+}
+
+func TestReleaseWhilePaused(t *testing.T) {
+	pool := NewGoroutinePool(2)
+	pool.Pause()
+
+	var executed atomic.Int32
+	for i := 0; i < 5; i++ {
+		pool.Submit(func() (interface{}, error) {
+			executed.Add(1)
+			return nil, nil
+		})
+	}
+	if err := pool.ReleaseWithTimeout(time.Second); err != nil {
+		t.Fatalf("release of a paused pool failed: %v", err)
+	}
+	if n := executed.Load(); n != 5 {
+		t.Fatalf("expected the queue to drain on release, %d of 5 tasks ran", n)
+	}
+}
+
+func TestWaitContext(t *testing.T) {
+	pool := NewGoroutinePool(2)
+	defer pool.Release()
+
+	pool.Submit(func() (interface{}, error) {
+		time.Sleep(100 * time.Millisecond)
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	pool.Wait()
+	if err := pool.WaitContext(context.Background()); err != nil {
+		t.Fatalf("expected a drained pool, got %v", err)
+	}
+}
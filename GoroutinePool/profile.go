@@ -0,0 +1,45 @@
+package GoroutinePool
+
+import "runtime"
+
+// Profile describes the kind of tasks a pool sized by NewDefaultPool runs
+type Profile int
+
+const (
+	// CPUBound 计算密集型任务，最大工作协程数为 GOMAXPROCS
+	CPUBound Profile = iota
+	// IOBound IO密集型任务，最大工作协程数为 GOMAXPROCS 的 ioBoundFactor 倍
+	IOBound
+)
+
+// ioBoundFactor is how many workers an IOBound pool runs per GOMAXPROCS,
+// as those workers spend most of their time waiting
+const ioBoundFactor = 8
+
+// NewDefaultPool returns a pool sized for this machine:
+//
+//	maxWorkers = GOMAXPROCS      for CPUBound (the default profile)
+//	maxWorkers = GOMAXPROCS * 8  for IOBound
+//	minWorkers = maxWorkers / 4, at least 1, unless WithMinWorkers is given
+//
+// The profile is chosen with WithProfile.
+func NewDefaultPool(options ...Option) *GoroutinePool {
+	return NewGoroutinePool(0, options...)
+}
+
+// autoSize sets the worker bounds of a pool created without maxWorkers,
+// see NewDefaultPool
+func (pool *GoroutinePool) autoSize() {
+	procs := pool.gomaxprocs
+	if procs <= 0 {
+		procs = runtime.GOMAXPROCS(0)
+	}
+	pool.maxWorkers = procs
+	if pool.profile == IOBound {
+		pool.maxWorkers *= ioBoundFactor
+	}
+	// 最小值不等于最大值，给自动扩缩容留出空间
+	if pool.minWorkers <= 0 {
+		pool.minWorkers = max(pool.maxWorkers/4, 1)
+	}
+}
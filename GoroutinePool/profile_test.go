@@ -0,0 +1,37 @@
+package GoroutinePool
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestDefaultPoolSizing(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []Option
+		min, max int
+	}{
+		{"cpu bound", []Option{WithGOMAXPROCS(8)}, 2, 8},
+		{"io bound", []Option{WithGOMAXPROCS(8), WithProfile(IOBound)}, 16, 64},
+		{"single proc", []Option{WithGOMAXPROCS(1)}, 1, 1},
+		{"explicit min", []Option{WithGOMAXPROCS(8), WithMinWorkers(3)}, 3, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewDefaultPool(tt.options...)
+			defer pool.Release()
+			if n := pool.GetWorkers(); n != tt.min {
+				t.Fatalf("expected %d workers to start, got %d", tt.min, n)
+			}
+			if pool.maxWorkers != tt.max {
+				t.Fatalf("expected a maximum of %d workers, got %d", tt.max, pool.maxWorkers)
+			}
+		})
+	}
+
+	pool := NewDefaultPool()
+	defer pool.Release()
+	if want := runtime.GOMAXPROCS(0); pool.maxWorkers != want {
+		t.Fatalf("expected GOMAXPROCS (%d) workers at most, got %d", want, pool.maxWorkers)
+	}
+}
@@ -0,0 +1,247 @@
+package GoroutinePool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned by TaskQueue.Push when a bounded queue has no room left
+var ErrQueueFull = errors.New("task queue is full")
+
+// ErrQueueClosed is returned by TaskQueue.Push once the queue has been closed
+var ErrQueueClosed = errors.New("task queue is closed")
+
+// QueuedTask is an element of a TaskQueue: a submitted task together with the
+// options it was submitted with. Queues may read it to decide the order in which
+// tasks leave, but must hand back the very pointers they received through Push.
+type QueuedTask struct {
+	item *taskItem
+}
+
+// Task returns the submitted task. Queues must not run it, the pool does.
+func (t *QueuedTask) Task() Task {
+	return t.item.task
+}
+
+// Key returns the key of a task submitted by SubmitKeyed, or ""
+func (t *QueuedTask) Key() string {
+	return t.item.key
+}
+
+// Priority returns the priority set by TaskPriority, zero by default
+func (t *QueuedTask) Priority() int {
+	return t.item.priority
+}
+
+// Expiry returns when the task expires in the queue, see WithQueueTimeout.
+// It is the zero time if the task never expires.
+func (t *QueuedTask) Expiry() time.Time {
+	return t.item.expiry
+}
+
+// TaskQueue holds submitted tasks until dispatch hands them to a worker.
+// Implementations must be safe for concurrent use; Pop is only called by dispatch.
+// Pop must hand back exactly the QueuedTasks received through Push, only the
+// order may differ. Anything else it returns is ignored.
+type TaskQueue interface {
+	// Push 添加任务，不阻塞。队列已满时返回 ErrQueueFull，已关闭时返回 ErrQueueClosed
+	Push(t *QueuedTask) error
+	// Pop 取出下一个任务，队列为空时阻塞。队列关闭且为空或 ctx 结束时返回 false。
+	// ctx 已结束时仍应返回已入队的任务
+	Pop(ctx context.Context) (*QueuedTask, bool)
+	// Len 获取队列中等待的任务数量
+	Len() int
+	// Close 关闭队列，已入队的任务仍可取出
+	Close()
+}
+
+// Evicter is implemented by queues that can give up a queued task to make room,
+// which RejectDropOldest requires. Evict is called by submitters, concurrently
+// with Push and Pop.
+type Evicter interface {
+	// Evict 取出最早入队的任务，不阻塞。队列为空时返回 false
+	Evict() (*QueuedTask, bool)
+}
+
+// capacity is implemented by queues with a fixed capacity
+type capacity interface {
+	Cap() int
+}
+
+// boundedQueue is a TaskQueue backed by a buffered channel
+type boundedQueue struct {
+	// lock guards tasks against being closed while a send is in progress
+	lock   sync.RWMutex
+	closed bool
+	tasks  chan *QueuedTask
+}
+
+// NewBoundedQueue returns a TaskQueue that holds at most size tasks.
+// This is the queue the pool uses unless WithQueue or WithUnboundedQueue is given.
+func NewBoundedQueue(size int) TaskQueue {
+	return &boundedQueue{tasks: make(chan *QueuedTask, size)}
+}
+
+func (q *boundedQueue) Push(t *QueuedTask) error {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.tasks <- t:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q *boundedQueue) Pop(ctx context.Context) (*QueuedTask, bool) {
+	// 优先取出已入队的任务，即使 ctx 已结束
+	select {
+	case t, ok := <-q.tasks:
+		return t, ok
+	default:
+	}
+	select {
+	case t, ok := <-q.tasks:
+		return t, ok
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+func (q *boundedQueue) Evict() (*QueuedTask, bool) {
+	select {
+	case t, ok := <-q.tasks:
+		return t, ok
+	default:
+		return nil, false
+	}
+}
+
+func (q *boundedQueue) Len() int {
+	return len(q.tasks)
+}
+
+func (q *boundedQueue) Cap() int {
+	return cap(q.tasks)
+}
+
+func (q *boundedQueue) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.tasks)
+	}
+}
+
+// unboundedQueue is a TaskQueue backed by a singly linked list
+type unboundedQueue struct {
+	lock   sync.Mutex
+	head   *queueNode
+	tail   *queueNode
+	length int
+	closed bool
+	// ready holds a token while tasks are waiting and is closed by Close
+	ready chan struct{}
+}
+
+type queueNode struct {
+	task *QueuedTask
+	next *queueNode
+}
+
+// NewUnboundedQueue returns a TaskQueue that grows as needed, so Push never
+// fails with ErrQueueFull. Memory is only held for the tasks currently queued.
+func NewUnboundedQueue() TaskQueue {
+	return &unboundedQueue{ready: make(chan struct{}, 1)}
+}
+
+func (q *unboundedQueue) Push(t *QueuedTask) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	node := &queueNode{task: t}
+	if q.tail == nil {
+		q.head = node
+	} else {
+		q.tail.next = node
+	}
+	q.tail = node
+	q.length++
+	q.signal()
+	return nil
+}
+
+func (q *unboundedQueue) Pop(ctx context.Context) (*QueuedTask, bool) {
+	for {
+		q.lock.Lock()
+		if t, ok := q.take(); ok {
+			q.lock.Unlock()
+			return t, true
+		}
+		closed := q.closed
+		q.lock.Unlock()
+		if closed {
+			return nil, false
+		}
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+func (q *unboundedQueue) Evict() (*QueuedTask, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.take()
+}
+
+// take removes the head of the list, the caller holds q.lock
+func (q *unboundedQueue) take() (*QueuedTask, bool) {
+	node := q.head
+	if node == nil {
+		return nil, false
+	}
+	q.head = node.next
+	if q.head == nil {
+		q.tail = nil
+	}
+	q.length--
+	if q.length > 0 && !q.closed {
+		// 仍有任务，把令牌交给下一个等待者
+		q.signal()
+	}
+	return node.task, true
+}
+
+// signal leaves a token in ready, the caller holds q.lock
+func (q *unboundedQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *unboundedQueue) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.length
+}
+
+func (q *unboundedQueue) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.ready)
+	}
+}
@@ -0,0 +1,261 @@
+package GoroutinePool
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBoundedQueue(t *testing.T) {
+	q := NewBoundedQueue(2)
+	task := &QueuedTask{item: &taskItem{}}
+	for i := 0; i < 2; i++ {
+		if err := q.Push(task); err != nil {
+			t.Fatalf("push %d: %v", i, err)
+		}
+	}
+	if err := q.Push(task); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if n := q.Len(); n != 2 {
+		t.Fatalf("expected 2 queued tasks, got %d", n)
+	}
+
+	q.Close()
+	if err := q.Push(task); err != ErrQueueClosed {
+		t.Fatalf("expected ErrQueueClosed, got %v", err)
+	}
+	// 关闭后已入队的任务仍可取出
+	for i := 0; i < 2; i++ {
+		if _, ok := q.Pop(context.Background()); !ok {
+			t.Fatalf("pop %d failed after Close", i)
+		}
+	}
+	if _, ok := q.Pop(context.Background()); ok {
+		t.Fatal("expected Pop to fail on a closed, empty queue")
+	}
+}
+
+func TestUnboundedQueuePopWaits(t *testing.T) {
+	q := NewUnboundedQueue()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, ok := q.Pop(ctx); ok {
+		t.Fatal("expected Pop on an empty queue to give up with ctx")
+	}
+
+	popped := make(chan *QueuedTask)
+	go func() {
+		task, _ := q.Pop(context.Background())
+		popped <- task
+	}()
+	time.Sleep(10 * time.Millisecond)
+	pushed := &QueuedTask{item: &taskItem{}}
+	q.Push(pushed)
+	select {
+	case task := <-popped:
+		if task != pushed {
+			t.Fatal("popped the wrong task")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Pop was not woken by Push")
+	}
+
+	q.Close()
+	if _, ok := q.Pop(context.Background()); ok {
+		t.Fatal("expected Pop to fail on a closed, empty queue")
+	}
+}
+
+func TestUnboundedQueueBurst(t *testing.T) {
+	const burst = 200000
+	q := NewUnboundedQueue()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	task := &QueuedTask{item: &taskItem{}}
+	for i := 0; i < burst; i++ {
+		if err := q.Push(task); err != nil {
+			t.Fatalf("push %d: %v", i, err)
+		}
+	}
+	if n := q.Len(); n != burst {
+		t.Fatalf("expected %d queued tasks, got %d", burst, n)
+	}
+	for i := 0; i < burst; i++ {
+		if _, ok := q.Pop(context.Background()); !ok {
+			t.Fatalf("pop %d failed", i)
+		}
+	}
+	if n := q.Len(); n != 0 {
+		t.Fatalf("expected an empty queue, got %d", n)
+	}
+
+	// 取空后不应再持有任何节点
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	if grown := int64(after.HeapAlloc) - int64(before.HeapAlloc); grown > 1<<20 {
+		t.Fatalf("drained queue still holds %d bytes", grown)
+	}
+	runtime.KeepAlive(q)
+}
+
+func TestQueueEvict(t *testing.T) {
+	for name, q := range map[string]TaskQueue{
+		"bounded":   NewBoundedQueue(2),
+		"unbounded": NewUnboundedQueue(),
+	} {
+		first, second := &QueuedTask{item: &taskItem{}}, &QueuedTask{item: &taskItem{}}
+		q.Push(first)
+		q.Push(second)
+		evicter := q.(Evicter)
+		if t1, ok := evicter.Evict(); !ok || t1 != first {
+			t.Fatalf("%s: expected Evict to give up the oldest task", name)
+		}
+		if n := q.Len(); n != 1 {
+			t.Fatalf("%s: expected 1 task left, got %d", name, n)
+		}
+		q.Pop(context.Background())
+		if _, ok := evicter.Evict(); ok {
+			t.Fatalf("%s: expected Evict on an empty queue to fail without blocking", name)
+		}
+	}
+}
+
+func TestPoolWithUnboundedQueue(t *testing.T) {
+	pool := NewGoroutinePool(1, WithUnboundedQueue())
+	defer pool.Release()
+
+	if n := pool.GetTaskQueueCap(); n != -1 {
+		t.Fatalf("expected -1 as the capacity of an unbounded queue, got %d", n)
+	}
+
+	unblock := blockWorker(pool)
+	// worker在忙，提交不能因为队列而阻塞
+	futures := make([]*Future, 10000)
+	for i := range futures {
+		i := i
+		futures[i] = pool.SubmitWithResult(func() (interface{}, error) { return i, nil })
+	}
+	unblock()
+	for i, future := range futures {
+		if result, err := future.Get(); result != i || err != nil {
+			t.Fatalf("task %d: got (%v, %v)", i, result, err)
+		}
+	}
+}
+
+// priorityQueue is a TaskQueue built only on the exported API. It hands out
+// the task of the highest priority first, in submission order among equals.
+type priorityQueue struct {
+	mu     sync.Mutex
+	tasks  []*QueuedTask
+	closed bool
+	ready  chan struct{}
+}
+
+func (q *priorityQueue) Push(t *QueuedTask) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.tasks = append(q.tasks, t)
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *priorityQueue) Pop(ctx context.Context) (*QueuedTask, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.tasks) > 0 {
+			next := 0
+			for i, t := range q.tasks {
+				if t.Priority() > q.tasks[next].Priority() {
+					next = i
+				}
+			}
+			t := q.tasks[next]
+			q.tasks = append(q.tasks[:next], q.tasks[next+1:]...)
+			q.mu.Unlock()
+			return t, true
+		}
+		closed := q.closed
+		q.mu.Unlock()
+		if closed {
+			return nil, false
+		}
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+func (q *priorityQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.tasks)
+}
+
+func (q *priorityQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.ready)
+	}
+}
+
+func TestPoolWithCustomQueue(t *testing.T) {
+	pool := NewGoroutinePool(1, WithQueue(&priorityQueue{ready: make(chan struct{}, 1)}))
+	unblock := blockWorker(pool)
+
+	var (
+		mu    sync.Mutex
+		order []int
+	)
+	futures := make([]*Future, 4)
+	for i := range futures {
+		i := i
+		futures[i] = pool.SubmitWithResult(func() (interface{}, error) {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			return i, nil
+		}, TaskPriority(i%3), TaskTimeout(time.Second))
+		if i == 0 {
+			// 等待 dispatch 取走第一个任务，它会一直等到worker空闲
+			for pool.GetTaskQueueLen() != 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+	if n := pool.GetTaskQueueLen(); n != 3 {
+		t.Fatalf("expected 3 queued tasks, got %d", n)
+	}
+	unblock()
+	for i, future := range futures {
+		if result, err := future.Get(); result != i || err != nil {
+			t.Fatalf("task %d: got (%v, %v)", i, result, err)
+		}
+	}
+	pool.Release()
+
+	// 优先级分别为 1、2、0
+	want := []int{0, 2, 1, 3}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected priority order %v, got %v", want, order)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package GoroutinePool
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket holding a single token that refills every
+// interval, so task starts are spaced evenly and no more than n of them fall
+// into any window of per
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *rateLimiter) set(n int, per time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n <= 0 || per <= 0 {
+		l.interval = 0
+		return
+	}
+	l.interval = per / time.Duration(n)
+}
+
+// getInterval returns the spacing between task starts, zero without a limit
+func (l *rateLimiter) getInterval() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.interval
+}
+
+// wait blocks until the next task may start, or until stop is closed
+func (l *rateLimiter) wait(stop <-chan struct{}) {
+	l.mu.Lock()
+	if l.interval <= 0 {
+		l.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := slot.Sub(now)
+	if delay <= 0 {
+		return
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-stop:
+	}
+}
+
+// SetRateLimit changes the rate limit of the pool at runtime.
+// A non-positive n or per removes the limit.
+func (pool *GoroutinePool) SetRateLimit(n int, per time.Duration) {
+	pool.rateLimiter.set(n, per)
+}
@@ -0,0 +1,88 @@
+package GoroutinePool
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	pool := NewGoroutinePool(4, WithRateLimit(10, 100*time.Millisecond))
+	defer pool.Release()
+
+	var (
+		mu     sync.Mutex
+		starts []time.Time
+	)
+	task := func() (interface{}, error) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		return nil, nil
+	}
+	for i := 0; i < 20; i++ {
+		pool.Submit(task)
+	}
+	pool.Wait()
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	if span := starts[len(starts)-1].Sub(starts[0]); span < 180*time.Millisecond {
+		t.Fatalf("20 tasks at 10 per 100ms started within %v", span)
+	}
+	for i := 10; i < len(starts); i++ {
+		// allow some slack for the hand-off from dispatcher to worker
+		if gap := starts[i].Sub(starts[i-10]); gap < 90*time.Millisecond {
+			t.Fatalf("11 tasks started within %v", gap)
+		}
+	}
+
+	pool.SetRateLimit(0, 0)
+	begin := time.Now()
+	for i := 0; i < 20; i++ {
+		pool.Submit(task)
+	}
+	pool.Wait()
+	if elapsed := time.Since(begin); elapsed > 50*time.Millisecond {
+		t.Fatalf("tasks were still throttled after removing the limit: %v", elapsed)
+	}
+}
+
+func TestRateLimitDoesNotBlockForcedRelease(t *testing.T) {
+	pool := NewGoroutinePool(1, WithRateLimit(1, time.Hour))
+	for i := 0; i < 3; i++ {
+		pool.Submit(func() (interface{}, error) { return nil, nil })
+	}
+
+	begin := time.Now()
+	pool.ReleaseWithTimeout(20 * time.Millisecond)
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("release waited %v on the rate limiter", elapsed)
+	}
+}
+
+func TestRateLimitWithStalledWorker(t *testing.T) {
+	pool := NewGoroutinePool(1, WithRateLimit(1, 200*time.Millisecond))
+	defer pool.Release()
+
+	pool.Submit(func() (interface{}, error) {
+		time.Sleep(600 * time.Millisecond)
+		return nil, nil
+	})
+	// 排队等待worker的时间不能抵扣限流间隔
+	starts := make([]time.Time, 2)
+	futures := make([]*Future, len(starts))
+	for i := range futures {
+		i := i
+		futures[i] = pool.SubmitWithResult(func() (interface{}, error) {
+			starts[i] = time.Now()
+			return nil, nil
+		})
+	}
+	for _, future := range futures {
+		future.Get()
+	}
+	if gap := starts[1].Sub(starts[0]); gap < 190*time.Millisecond {
+		t.Fatalf("tasks queued behind a stalled worker started %v apart", gap)
+	}
+}
@@ -0,0 +1,69 @@
+package GoroutinePool
+
+import "errors"
+
+// ErrTaskDropped fails the Future of a queued task evicted by RejectDropOldest
+var ErrTaskDropped = errors.New("task dropped from a full queue")
+
+// RejectionPolicy decides what happens to a task submitted while the task queue is full.
+// TrySubmit never waits and ignores the policy.
+type RejectionPolicy int
+
+const (
+	// RejectBlock 阻塞提交者直到队列有空位
+	RejectBlock RejectionPolicy = iota
+	// RejectError 立即返回 ErrQueueFull
+	RejectError
+	// RejectDropOldest 丢弃队列中最早的任务，再放入新任务。队列未实现 Evicter 时同 RejectError
+	RejectDropOldest
+	// RejectCallerRuns 在提交者的协程中直接执行任务，同样受速率限制。
+	// 按key提交的任务以及协程池暂停期间仍然阻塞，以保证同key任务的顺序和暂停的语义
+	RejectCallerRuns
+)
+
+// pushDroppingOldest evicts the oldest queued task until task fits into the queue.
+// A queue that is not an Evicter cannot make room, task is rejected instead.
+func (pool *GoroutinePool) pushDroppingOldest(task *QueuedTask) error {
+	q, ok := pool.taskQueue.(Evicter)
+	if !ok {
+		pool.stats.rejected.Add(1)
+		return ErrQueueFull
+	}
+	for {
+		if t, ok := q.Evict(); ok && t != nil && t.item != nil {
+			pool.dropQueued(t.item)
+		}
+		if err := pool.taskQueue.Push(task); err != ErrQueueFull {
+			return err
+		}
+	}
+}
+
+// dropQueued discards an item evicted from the queue
+func (pool *GoroutinePool) dropQueued(item *taskItem) {
+	pool.dequeued(item)
+	// 已被取消的任务不算作拒绝
+	if item.discard(ErrTaskDropped) {
+		pool.stats.rejected.Add(1)
+		if pool.dropCallback != nil {
+			pool.dropCallback(item.task)
+		}
+	}
+	pool.donePending()
+}
+
+// runInCaller executes item on the submitting goroutine the way a worker would,
+// once the rate limit lets it start
+func (pool *GoroutinePool) runInCaller(item *taskItem) {
+	var w Worker
+	pool.rateLimiter.wait(pool.stopCtx.Done())
+	if item.start() {
+		pool.running.Add(1)
+		result, err := w.execute(item, pool)
+		pool.running.Add(-1)
+		w.handleResult(result, err, pool)
+		item.complete(result, err)
+		item.finish()
+	}
+	pool.donePending()
+}
@@ -0,0 +1,238 @@
+package GoroutinePool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fillQueue blocks the only worker of pool, parks one task in dispatch and fills
+// the size-2 queue with two more. It returns the futures of those three tasks
+// and a function unblocking the worker.
+func fillQueue(t *testing.T, pool *GoroutinePool) ([]*Future, func()) {
+	t.Helper()
+	unblock := blockWorker(pool)
+
+	futures := make([]*Future, 3)
+	for i := range futures {
+		i := i
+		futures[i] = pool.SubmitWithResult(func() (interface{}, error) { return i, nil })
+		if i == 0 {
+			// 等待 dispatch 取走第一个任务
+			for pool.GetTaskQueueLen() != 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+	if n := pool.GetTaskQueueLen(); n != 2 {
+		t.Fatalf("expected a full queue of 2, got %d", n)
+	}
+	return futures, unblock
+}
+
+func TestRejectBlock(t *testing.T) {
+	pool := NewGoroutinePool(1, WithTaskQueueSize(2))
+	defer pool.Release()
+	_, unblock := fillQueue(t, pool)
+
+	submitted := make(chan error)
+	go func() {
+		submitted <- pool.Submit(func() (interface{}, error) { return nil, nil })
+	}()
+	select {
+	case err := <-submitted:
+		t.Fatalf("Submit returned %v on a full queue", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	unblock()
+	if err := <-submitted; err != nil {
+		t.Fatalf("expected Submit to succeed once there is room, got %v", err)
+	}
+}
+
+func TestRejectError(t *testing.T) {
+	pool := NewGoroutinePool(1, WithTaskQueueSize(2), WithRejectionPolicy(RejectError))
+	defer pool.Release()
+	futures, unblock := fillQueue(t, pool)
+
+	if err := pool.Submit(func() (interface{}, error) { return nil, nil }); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if _, err := pool.SubmitWithResult(func() (interface{}, error) { return nil, nil }).Get(); err != ErrQueueFull {
+		t.Fatalf("expected the future to fail with ErrQueueFull, got %v", err)
+	}
+	unblock()
+	for i, future := range futures {
+		if result, err := future.Get(); result != i || err != nil {
+			t.Fatalf("task %d: got (%v, %v)", i, result, err)
+		}
+	}
+	if stats := pool.Stats(); stats.RejectedTasks != 2 || stats.SubmittedTasks != 4 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRejectDropOldest(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		dropped []Task
+	)
+	pool := NewGoroutinePool(1,
+		WithTaskQueueSize(2),
+		WithRejectionPolicy(RejectDropOldest),
+		WithDropCallback(func(task Task) {
+			mu.Lock()
+			dropped = append(dropped, task)
+			mu.Unlock()
+		}),
+	)
+	defer pool.Release()
+	futures, unblock := fillQueue(t, pool)
+
+	latest := pool.SubmitWithResult(func() (interface{}, error) { return "latest", nil })
+	unblock()
+
+	// 任务1是队列中最早的任务，被丢弃
+	if _, err := futures[1].Get(); err != ErrTaskDropped {
+		t.Fatalf("expected the oldest queued task to fail with ErrTaskDropped, got %v", err)
+	}
+	for _, i := range []int{0, 2} {
+		if result, err := futures[i].Get(); result != i || err != nil {
+			t.Fatalf("task %d: got (%v, %v)", i, result, err)
+		}
+	}
+	if result, err := latest.Get(); result != "latest" || err != nil {
+		t.Fatalf("expected the new task to run, got (%v, %v)", result, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dropped) != 1 {
+		t.Fatalf("expected the drop callback once, got %d", len(dropped))
+	}
+	if result, _ := dropped[0](); result != 1 {
+		t.Fatalf("the drop callback got the wrong task, it returns %v", result)
+	}
+	if stats := pool.Stats(); stats.RejectedTasks != 1 || stats.CompletedTasks != 4 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRejectDropOldestWithoutEvicter(t *testing.T) {
+	// 只暴露 TaskQueue 的方法，不支持 Evict
+	q := struct{ TaskQueue }{NewBoundedQueue(2)}
+	pool := NewGoroutinePool(1, WithQueue(q), WithRejectionPolicy(RejectDropOldest))
+	defer pool.Release()
+	futures, unblock := fillQueue(t, pool)
+
+	if err := pool.Submit(func() (interface{}, error) { return nil, nil }); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull from a queue that cannot evict, got %v", err)
+	}
+	unblock()
+	for i, future := range futures {
+		if result, err := future.Get(); result != i || err != nil {
+			t.Fatalf("task %d: got (%v, %v)", i, result, err)
+		}
+	}
+	if n := pool.Stats().RejectedTasks; n != 1 {
+		t.Fatalf("expected 1 rejected task, got %d", n)
+	}
+}
+
+func TestRejectCallerRuns(t *testing.T) {
+	var results []interface{}
+	pool := NewGoroutinePool(1,
+		WithTaskQueueSize(2),
+		WithRejectionPolicy(RejectCallerRuns),
+		WithResultCallback(func(result interface{}) {
+			results = append(results, result)
+		}),
+	)
+	defer pool.Release()
+	futures, unblock := fillQueue(t, pool)
+
+	// 唯一的worker被阻塞，任务只能在提交者的协程中执行
+	future := pool.SubmitWithResult(func() (interface{}, error) { return "caller", nil })
+	select {
+	case <-future.Done():
+	default:
+		t.Fatal("expected the task to have run by the time Submit returned")
+	}
+	if result, err := future.Get(); result != "caller" || err != nil {
+		t.Fatalf("got (%v, %v)", result, err)
+	}
+	if len(results) != 1 || results[0] != "caller" {
+		t.Fatalf("expected the result callback for the caller-run task, got %v", results)
+	}
+	if stats := pool.Stats(); stats.CompletedTasks != 1 || stats.RejectedTasks != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	unblock()
+	for i, future := range futures {
+		if result, err := future.Get(); result != i || err != nil {
+			t.Fatalf("task %d: got (%v, %v)", i, result, err)
+		}
+	}
+}
+
+func TestRejectCallerRunsPaused(t *testing.T) {
+	pool := NewGoroutinePool(1, WithTaskQueueSize(2), WithRejectionPolicy(RejectCallerRuns))
+	defer pool.Release()
+	_, unblock := fillQueue(t, pool)
+	pool.Pause()
+
+	// 暂停期间退回到阻塞提交
+	var ran atomic.Bool
+	submitted := make(chan error)
+	go func() {
+		submitted <- pool.Submit(func() (interface{}, error) {
+			ran.Store(true)
+			return nil, nil
+		})
+	}()
+	select {
+	case err := <-submitted:
+		t.Fatalf("Submit returned %v while the pool was paused", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if ran.Load() {
+		t.Fatal("a caller-run task ran while the pool was paused")
+	}
+
+	pool.Resume()
+	unblock()
+	if err := <-submitted; err != nil {
+		t.Fatal(err)
+	}
+	pool.Wait()
+	if !ran.Load() {
+		t.Fatal("expected the task to run after Resume")
+	}
+}
+
+func TestRejectCallerRunsRateLimit(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	pool := NewGoroutinePool(1,
+		WithTaskQueueSize(2),
+		WithRejectionPolicy(RejectCallerRuns),
+		WithRateLimit(1, interval),
+	)
+	defer pool.Release()
+	_, unblock := fillQueue(t, pool)
+	defer unblock()
+
+	var starts []time.Time
+	for i := 0; i < 3; i++ {
+		pool.Submit(func() (interface{}, error) {
+			starts = append(starts, time.Now())
+			return nil, nil
+		})
+	}
+	for i := 1; i < len(starts); i++ {
+		// 允许计时器的少量误差
+		if gap := starts[i].Sub(starts[i-1]); gap < interval*3/4 {
+			t.Fatalf("caller-run task %d started %v after the previous one, expected about %v", i, gap, interval)
+		}
+	}
+}
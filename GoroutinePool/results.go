@@ -0,0 +1,44 @@
+package GoroutinePool
+
+import "sync"
+
+// resultStream delivers the outcome of every task on a buffered channel
+type resultStream struct {
+	mu     sync.Mutex
+	ch     chan TaskResult
+	closed bool
+}
+
+// Results returns the channel enabled by WithResultChannel, which is closed once
+// Release has finished. It returns nil if the option was not set.
+func (pool *GoroutinePool) Results() <-chan TaskResult {
+	if pool.results == nil {
+		return nil
+	}
+	return pool.results.ch
+}
+
+// publish sends r without blocking, so a slow consumer never stalls a worker.
+// It returns false if r had to be dropped.
+func (s *resultStream) publish(r TaskResult) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	select {
+	case s.ch <- r:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *resultStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
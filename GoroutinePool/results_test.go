@@ -0,0 +1,56 @@
+package GoroutinePool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultChannel(t *testing.T) {
+	pool := NewGoroutinePool(4, WithResultChannel(100))
+
+	consumed := make(chan int)
+	go func() {
+		sum := 0
+		for r := range pool.Results() {
+			sum += r.Result.(int)
+		}
+		consumed <- sum
+	}()
+
+	want := 0
+	for i := 0; i < 100; i++ {
+		i := i
+		want += i
+		pool.Submit(func() (interface{}, error) { return i, nil })
+	}
+	pool.Release()
+
+	select {
+	case sum := <-consumed:
+		if sum != want {
+			t.Fatalf("consumed results add up to %d, want %d", sum, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("results channel was not closed by Release")
+	}
+	if n := pool.Stats().DroppedResults; n != 0 {
+		t.Fatalf("%d results were dropped with an active consumer", n)
+	}
+}
+
+func TestResultChannelDropsWhenFull(t *testing.T) {
+	pool := NewGoroutinePool(2, WithResultChannel(1))
+	defer pool.Release()
+
+	for i := 0; i < 5; i++ {
+		pool.Submit(func() (interface{}, error) { return nil, nil })
+	}
+	pool.Wait()
+
+	if n := pool.Stats().DroppedResults; n != 4 {
+		t.Fatalf("expected 4 dropped results, got %d", n)
+	}
+	if n := len(pool.Results()); n != 1 {
+		t.Fatalf("expected 1 buffered result, got %d", n)
+	}
+}
@@ -0,0 +1,27 @@
+package GoroutinePool
+
+// QueueUtilization returns the share of the task queue capacity in use, from 0
+// to 1. It returns -1 for queues without a fixed capacity, such as the unbounded queue.
+func (pool *GoroutinePool) QueueUtilization() float64 {
+	capacity := pool.GetTaskQueueCap()
+	if capacity <= 0 {
+		return -1
+	}
+	return float64(pool.queueLen()) / float64(capacity)
+}
+
+// checkSaturation reports a crossing of the saturation threshold to the callback
+func (pool *GoroutinePool) checkSaturation() {
+	if pool.saturationCallback == nil {
+		return
+	}
+	utilization := pool.QueueUtilization()
+	if utilization < 0 {
+		return
+	}
+	// 只在越过阈值时通知
+	if saturated := utilization >= pool.saturationThreshold; saturated != pool.saturated {
+		pool.saturated = saturated
+		pool.saturationCallback(saturated)
+	}
+}
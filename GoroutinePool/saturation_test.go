@@ -0,0 +1,60 @@
+package GoroutinePool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueueUtilization(t *testing.T) {
+	pool := NewGoroutinePool(1, WithTaskQueueSize(4))
+	defer pool.Release()
+	_, unblock := fillQueue(t, pool)
+	defer unblock()
+
+	if u := pool.QueueUtilization(); u != 0.5 {
+		t.Fatalf("expected 2 of 4 slots in use, got %v", u)
+	}
+
+	unbounded := NewGoroutinePool(1, WithUnboundedQueue())
+	defer unbounded.Release()
+	if u := unbounded.QueueUtilization(); u != -1 {
+		t.Fatalf("expected -1 for an unbounded queue, got %v", u)
+	}
+}
+
+func TestSaturationCallback(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []bool
+	)
+	pool := NewGoroutinePool(1,
+		WithTaskQueueSize(4),
+		WithSaturationCallback(0.5, func(saturated bool) {
+			mu.Lock()
+			events = append(events, saturated)
+			mu.Unlock()
+		}),
+		WithAdjustInterval(time.Millisecond),
+	)
+	defer pool.Release()
+	eventsSeen := func() []bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]bool(nil), events...)
+	}
+
+	_, unblock := fillQueue(t, pool)
+	// 多个周期内保持饱和，只通知一次
+	time.Sleep(50 * time.Millisecond)
+	if got := eventsSeen(); len(got) != 1 || !got[0] {
+		t.Fatalf("expected a single saturation event, got %v", got)
+	}
+
+	unblock()
+	pool.Wait()
+	time.Sleep(50 * time.Millisecond)
+	if got := eventsSeen(); len(got) != 2 || got[1] {
+		t.Fatalf("expected a single recovery event after the saturation, got %v", got)
+	}
+}
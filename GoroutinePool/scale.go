@@ -0,0 +1,46 @@
+package GoroutinePool
+
+// defaultScaleUpThreshold grows the pool once more than 3 tasks are queued for every 4 workers
+const defaultScaleUpThreshold = 0.75
+
+// ScaleDownPolicy decides how many workers the adjuster retires on a tick once
+// the queue is empty and every worker is idle. It is not used when
+// WithWorkerIdleTimeout is set, which retires workers by their idle time instead.
+type ScaleDownPolicy int
+
+const (
+	// ScaleDownHalf 每次停止超出最小数量部分的一半
+	ScaleDownHalf ScaleDownPolicy = iota
+	// ScaleDownOne 每次只停止一个worker
+	ScaleDownOne
+	// ScaleDownToMin 一次缩容到最小数量
+	ScaleDownToMin
+	// ScaleDownNever 不因空闲而缩容，Resize 降低上限时仍会缩容到上限
+	ScaleDownNever
+)
+
+// shouldScaleUp reports whether the queue is long enough for the pool to grow.
+// The caller must hold pool.lock.
+func (pool *GoroutinePool) shouldScaleUp() bool {
+	return float64(pool.queueLen()) > pool.scaleUpThreshold*float64(len(pool.workers)) &&
+		len(pool.workers) < pool.maxWorkers
+}
+
+// scaleDownCount returns how many of the idle workers to retire, always enough
+// to get down to maxWorkers and never below minWorkers. The caller must hold pool.lock.
+func (pool *GoroutinePool) scaleDownCount() int {
+	surplus := len(pool.workers) - pool.minWorkers
+	var n int
+	switch pool.scaleDownPolicy {
+	case ScaleDownOne:
+		n = 1
+	case ScaleDownToMin:
+		n = surplus
+	case ScaleDownNever:
+		n = 0
+	default:
+		n = (surplus + 1) / 2
+	}
+	// Resize 降低了上限时直接缩容到上限
+	return min(max(n, len(pool.workers)-pool.maxWorkers), surplus)
+}
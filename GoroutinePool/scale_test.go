@@ -0,0 +1,142 @@
+package GoroutinePool
+
+import (
+	"testing"
+	"time"
+)
+
+// waitWorkers polls until pool has n workers or a second has passed
+func waitWorkers(t *testing.T, pool *GoroutinePool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for pool.GetWorkers() != n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pool.GetWorkers(); got != n {
+		t.Fatalf("expected %d workers, got %d", n, got)
+	}
+}
+
+func TestScaleUpThreshold(t *testing.T) {
+	pool := NewGoroutinePool(4,
+		WithMinWorkers(1),
+		WithAdjustInterval(10*time.Millisecond),
+		WithScaleUpThreshold(2),
+	)
+	defer pool.Release()
+
+	hold := make(chan struct{})
+	defer close(hold)
+	submit := func() {
+		pool.Submit(func() (interface{}, error) {
+			<-hold
+			return nil, nil
+		})
+	}
+	unblock := blockWorker(pool)
+	defer unblock()
+	// 一个任务在 dispatch 中等待，两个任务在队列中
+	for i := 0; i < 3; i++ {
+		submit()
+	}
+	for pool.GetTaskQueueLen() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := pool.GetWorkers(); n != 1 {
+		t.Fatalf("expected no growth with 2 queued tasks per worker, got %d workers", n)
+	}
+
+	// 超过阈值后扩容一倍，新worker取走一个任务后队列重新回到阈值以下
+	submit()
+	waitWorkers(t, pool, 2)
+	time.Sleep(50 * time.Millisecond)
+	if n := pool.GetWorkers(); n != 2 {
+		t.Fatalf("expected the pool to stay at 2 workers, got %d", n)
+	}
+}
+
+func TestScaleDownPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ScaleDownPolicy
+		want   int
+	}{
+		{"to min", ScaleDownToMin, 2},
+		{"never", ScaleDownNever, 8},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewGoroutinePool(8,
+				WithMinWorkers(2),
+				WithAdjustInterval(10*time.Millisecond),
+				WithScaleUpThreshold(0),
+				WithScaleDownPolicy(tt.policy),
+			)
+			defer pool.Release()
+
+			// 排队的任务让协程池扩容到上限
+			unblock := make(chan struct{})
+			for i := 0; i < 16; i++ {
+				pool.Submit(func() (interface{}, error) {
+					<-unblock
+					return nil, nil
+				})
+			}
+			waitWorkers(t, pool, 8)
+
+			close(unblock)
+			pool.Wait()
+			if tt.want != 8 {
+				waitWorkers(t, pool, tt.want)
+			}
+			time.Sleep(50 * time.Millisecond)
+			if n := pool.GetWorkers(); n != tt.want {
+				t.Fatalf("expected %d workers once idle, got %d", tt.want, n)
+			}
+		})
+	}
+}
+
+func TestScaleDownCount(t *testing.T) {
+	tests := []struct {
+		policy                    ScaleDownPolicy
+		workers, minW, maxW, want int
+	}{
+		{ScaleDownHalf, 8, 1, 8, 4},
+		{ScaleDownHalf, 2, 1, 8, 1},
+		{ScaleDownOne, 8, 1, 8, 1},
+		{ScaleDownToMin, 8, 3, 8, 5},
+		{ScaleDownNever, 8, 1, 8, 0},
+		// Resize 降低了上限，任何策略都要缩容到上限
+		{ScaleDownOne, 8, 1, 4, 4},
+		{ScaleDownNever, 8, 1, 4, 4},
+	}
+	for _, tt := range tests {
+		pool := &GoroutinePool{
+			workers:         make([]*Worker, tt.workers),
+			minWorkers:      tt.minW,
+			maxWorkers:      tt.maxW,
+			scaleDownPolicy: tt.policy,
+		}
+		if got := pool.scaleDownCount(); got != tt.want {
+			t.Errorf("policy %d with %d workers in [%d, %d]: retired %d, want %d",
+				tt.policy, tt.workers, tt.minW, tt.maxW, got, tt.want)
+		}
+	}
+}
+
+func TestScaleOptionsValidation(t *testing.T) {
+	pool := NewGoroutinePool(1,
+		WithAdjustInterval(0),
+		WithScaleUpThreshold(-1),
+		WithScaleDownPolicy(ScaleDownPolicy(42)),
+	)
+	defer pool.Release()
+	if pool.adjustInterval != time.Second || pool.scaleUpThreshold != defaultScaleUpThreshold ||
+		pool.scaleDownPolicy != ScaleDownHalf {
+		t.Fatalf("invalid values were not ignored: interval %v, threshold %v, policy %d",
+			pool.adjustInterval, pool.scaleUpThreshold, pool.scaleDownPolicy)
+	}
+}
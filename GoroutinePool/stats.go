@@ -0,0 +1,60 @@
+package GoroutinePool
+
+import "sync/atomic"
+
+// Stats is a point-in-time snapshot of what the pool has done so far
+type Stats struct {
+	// SubmittedTasks counts tasks accepted into the task queue
+	SubmittedTasks int64
+	// CompletedTasks counts tasks that finished without an error
+	CompletedTasks int64
+	// FailedTasks counts tasks that still returned an error after all retries
+	FailedTasks int64
+	// RetriedAttempts counts executions beyond the first attempt of a task
+	RetriedAttempts int64
+	// TimedOutTasks counts failed tasks whose final attempt timed out
+	TimedOutTasks int64
+	// DroppedResults counts results WithResultChannel could not deliver because its buffer was full
+	DroppedResults int64
+	// RejectedTasks counts tasks turned away or evicted by the rejection policy on a full queue
+	RejectedTasks int64
+	// ExpiredTasks counts tasks discarded for waiting in the queue longer than the queue timeout
+	ExpiredTasks int64
+
+	CurrentQueueLength int
+	CurrentWorkers     int
+	BusyWorkers        int
+}
+
+// poolStats holds the counters behind Stats
+type poolStats struct {
+	submitted atomic.Int64
+	completed atomic.Int64
+	failed    atomic.Int64
+	retried   atomic.Int64
+	timedOut  atomic.Int64
+
+	droppedResults atomic.Int64
+	rejected       atomic.Int64
+	expired        atomic.Int64
+}
+
+// Stats returns a snapshot of the pool counters
+func (pool *GoroutinePool) Stats() Stats {
+	stats := Stats{
+		SubmittedTasks:     pool.stats.submitted.Load(),
+		CompletedTasks:     pool.stats.completed.Load(),
+		FailedTasks:        pool.stats.failed.Load(),
+		RetriedAttempts:    pool.stats.retried.Load(),
+		TimedOutTasks:      pool.stats.timedOut.Load(),
+		DroppedResults:     pool.stats.droppedResults.Load(),
+		RejectedTasks:      pool.stats.rejected.Load(),
+		ExpiredTasks:       pool.stats.expired.Load(),
+		CurrentQueueLength: pool.queueLen(),
+		BusyWorkers:        pool.GetRunning(),
+	}
+	pool.lock.Lock()
+	stats.CurrentWorkers = len(pool.workers)
+	pool.lock.Unlock()
+	return stats
+}
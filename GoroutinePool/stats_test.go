@@ -0,0 +1,44 @@
+package GoroutinePool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	pool := NewGoroutinePool(2, WithRetryCount(1))
+	defer pool.Release()
+
+	var futures []*Future
+	for i := 0; i < 3; i++ {
+		futures = append(futures, pool.SubmitWithResult(func() (interface{}, error) {
+			return nil, nil
+		}))
+	}
+	for i := 0; i < 2; i++ {
+		futures = append(futures, pool.SubmitWithResult(func() (interface{}, error) {
+			return nil, errors.New("failed")
+		}))
+	}
+	futures = append(futures, pool.SubmitWithResult(func() (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		return nil, nil
+	}, TaskTimeout(5*time.Millisecond)))
+	for _, future := range futures {
+		future.Get()
+	}
+
+	stats := pool.Stats()
+	want := Stats{
+		SubmittedTasks:  6,
+		CompletedTasks:  3,
+		FailedTasks:     3,
+		RetriedAttempts: 3,
+		TimedOutTasks:   1,
+		CurrentWorkers:  2,
+	}
+	if stats != want {
+		t.Fatalf("got %+v, want %+v", stats, want)
+	}
+}
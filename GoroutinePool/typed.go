@@ -0,0 +1,95 @@
+package GoroutinePool
+
+import "context"
+
+// TypedTask is a task returning a result of type T
+type TypedTask[T any] func() (T, error)
+
+// TypedPool is a GoroutinePool whose tasks return results of type T.
+// Methods not shadowed here are those of the underlying pool.
+type TypedPool[T any] struct {
+	*GoroutinePool
+}
+
+// NewTypedPool creates a pool for tasks returning T
+func NewTypedPool[T any](maxWorkers int, options ...Option) *TypedPool[T] {
+	return &TypedPool[T]{GoroutinePool: NewGoroutinePool(maxWorkers, options...)}
+}
+
+// WithTypedResultCallback sets a result callback receiving results as T.
+// A nil result is passed as the zero value of T.
+func WithTypedResultCallback[T any](callback func(T)) Option {
+	return WithResultCallback(func(result interface{}) {
+		value, _ := result.(T)
+		callback(value)
+	})
+}
+
+// Submit submits a typed task, see GoroutinePool.Submit
+func (p *TypedPool[T]) Submit(task TypedTask[T]) error {
+	return p.GoroutinePool.Submit(task.box())
+}
+
+// SubmitWithResult submits a typed task and returns a future of its result
+func (p *TypedPool[T]) SubmitWithResult(task TypedTask[T], opts ...TaskOption) *TypedFuture[T] {
+	return &TypedFuture[T]{future: p.GoroutinePool.SubmitWithResult(task.box(), opts...)}
+}
+
+// SubmitOrdered submits typed tasks and returns their futures in submission order
+func (p *TypedPool[T]) SubmitOrdered(tasks []TypedTask[T]) []*TypedFuture[T] {
+	futures := make([]*TypedFuture[T], len(tasks))
+	for i, task := range tasks {
+		futures[i] = p.SubmitWithResult(task)
+	}
+	return futures
+}
+
+func (task TypedTask[T]) box() Task {
+	return func() (interface{}, error) {
+		return task()
+	}
+}
+
+// TypedFuture is a Future whose result has type T
+type TypedFuture[T any] struct {
+	future *Future
+}
+
+// Done returns a channel that is closed once the task has finished
+func (f *TypedFuture[T]) Done() <-chan struct{} {
+	return f.future.Done()
+}
+
+// Get blocks until the task has finished and returns its result and error
+func (f *TypedFuture[T]) Get() (T, error) {
+	return unbox[T](f.future.Get())
+}
+
+// GetWithContext is like Get but gives up with ctx.Err() when ctx is done first
+func (f *TypedFuture[T]) GetWithContext(ctx context.Context) (T, error) {
+	return unbox[T](f.future.GetWithContext(ctx))
+}
+
+func unbox[T any](result interface{}, err error) (T, error) {
+	value, _ := result.(T)
+	return value, err
+}
+
+// Map applies fn to every item on the pool and waits for all of them.
+// outputs[i] and errs[i] belong to items[i]; a failing item does not stop the others.
+func Map[In, Out any](pool *GoroutinePool, items []In, fn func(In) (Out, error)) ([]Out, []error) {
+	tasks := make([]Task, len(items))
+	for i, item := range items {
+		item := item
+		tasks[i] = TypedTask[Out](func() (Out, error) {
+			return fn(item)
+		}).box()
+	}
+	results := pool.SubmitAll(tasks).Results()
+	outputs := make([]Out, len(items))
+	errs := make([]error, len(items))
+	for i, r := range results {
+		outputs[i], errs[i] = unbox[Out](r.Result, r.Err)
+	}
+	return outputs, errs
+}
@@ -0,0 +1,91 @@
+package GoroutinePool
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTypedPoolParallelSums(t *testing.T) {
+	var callbackTotal atomic.Int64
+	pool := NewTypedPool[int](4, WithTypedResultCallback(func(sum int) {
+		callbackTotal.Add(int64(sum))
+	}))
+	defer pool.Release()
+
+	const chunks, chunkSize = 10, 100
+	tasks := make([]TypedTask[int], chunks)
+	for c := range tasks {
+		from := c * chunkSize
+		tasks[c] = func() (int, error) {
+			sum := 0
+			for n := from; n < from+chunkSize; n++ {
+				sum += n
+			}
+			return sum, nil
+		}
+	}
+
+	total := 0
+	for c, future := range pool.SubmitOrdered(tasks) {
+		sum, err := future.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := chunkSize*c*chunkSize + chunkSize*(chunkSize-1)/2; sum != want {
+			t.Fatalf("chunk %d: got %d, want %d", c, sum, want)
+		}
+		total += sum
+	}
+	if want := chunks * chunkSize * (chunks*chunkSize - 1) / 2; total != want {
+		t.Fatalf("got total %d, want %d", total, want)
+	}
+	if got := callbackTotal.Load(); got != int64(total) {
+		t.Fatalf("typed callback saw %d, want %d", got, total)
+	}
+}
+
+func TestMap(t *testing.T) {
+	const workers = 8
+	pool := NewGoroutinePool(workers)
+	defer pool.Release()
+
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i
+	}
+	var running, peak atomic.Int32
+	errOdd := errors.New("odd")
+	outputs, errs := Map(pool, items, func(n int) (string, error) {
+		cur := running.Add(1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Microsecond)
+		running.Add(-1)
+		if n%2 == 1 {
+			return "", errOdd
+		}
+		return strconv.Itoa(n), nil
+	})
+
+	for i := range items {
+		if i%2 == 1 {
+			if errs[i] != errOdd {
+				t.Fatalf("item %d: expected %v, got %v", i, errOdd, errs[i])
+			}
+			continue
+		}
+		if outputs[i] != strconv.Itoa(i) || errs[i] != nil {
+			t.Fatalf("item %d: got (%q, %v)", i, outputs[i], errs[i])
+		}
+	}
+	if n := peak.Load(); n > workers {
+		t.Fatalf("%d items ran concurrently on %d workers", n, workers)
+	}
+}
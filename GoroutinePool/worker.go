@@ -3,94 +3,215 @@ package GoroutinePool
 import (
 	"context"
 	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
 )
 
+// PanicError is the error reported for a task that panicked
+type PanicError struct {
+	Recovered interface{}
+	Stack     []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panic: %v\n%s", e.Recovered, e.Stack)
+}
+
 type Worker struct {
-	taskQueue chan Task
+	taskQueue chan *taskItem
+	// id numbers the workers of a pool in creation order, see DispatchRoundRobin
+	id int
+	// state is created by the worker init hook, see WithWorkerInit
+	state interface{}
+	// lastActive is when the worker last returned to the pool, guarded by pool.lock
+	lastActive time.Time
 }
 
 func newWorker() *Worker {
 	return &Worker{
-		taskQueue: make(chan Task, 1),
+		taskQueue: make(chan *taskItem, 1),
 	}
 }
 
 // start starts the worker in a separate goroutine.
 // The worker will run Tasks from its taskQueue until the taskQueue is closed.
 // For the length of the taskQueue is 1, the worker will be pushed back to the pool after executing 1 Task
-func (w *Worker) start(pool *GoroutinePool, workerIndex int) {
+func (w *Worker) start(pool *GoroutinePool) {
 	go func() {
-		for t := range w.taskQueue {
-			if t != nil {
-				result, err := w.executeTask(t, pool)
-				w.handleResult(result, err, pool)
+		for item := range w.taskQueue {
+			// 任务在开始前已被取消，直接跳过
+			if item.start() {
+				var (
+					result interface{}
+					err    error
+				)
+				if item.task != nil {
+					pool.running.Add(1)
+					result, err = w.execute(item, pool)
+					// 最终结果已确定，在通知等待者之前减少计数
+					pool.running.Add(-1)
+					w.handleResult(result, err, pool)
+				}
+				item.complete(result, err)
+				item.finish()
 			}
+			pool.releaseKey(item)
+			pool.donePending()
 			// 虽然还有任务，但当前worker可以被重新分发任务，因此视作是归还了任务
-			pool.pushWorker(workerIndex)
+			pool.pushWorker(w)
+		}
+		// worker被缩容或协程池已释放
+		if pool.workerTeardown != nil {
+			pool.runHook(func() { pool.workerTeardown(w.state) })
 		}
+		pool.workerGroup.Done()
 	}()
 }
 
-func (w *Worker) executeTask(t Task, pool *GoroutinePool) (interface{}, error) {
+func (w *Worker) executeTask(item *taskItem, pool *GoroutinePool) (interface{}, error) {
+	timeout := pool.timeout
+	if item.hasTimeout {
+		timeout = item.timeout
+	}
+	var (
+		lastResult interface{}
+		lastErr    error
+	)
 	for i := 0; i <= pool.retryCount; i++ {
+		if i > 0 {
+			// 协程池正在释放时放弃剩余的重试
+			if !w.waitBackoff(pool, i) {
+				break
+			}
+			pool.stats.retried.Add(1)
+		}
 		var (
 			result interface{}
 			err    error
 		)
-		if pool.timeout > 0 {
-			result, err = w.executeTaskWithTimeout(t, pool)
-		} else {
-			result, err = w.executeTaskWithoutTimeout(t)
+		result, err = w.attempt(item, timeout, pool)
+		if err == nil {
+			return result, nil
 		}
-		if err == nil || i == pool.retryCount {
+		lastResult, lastErr = result, err
+	}
+	// Every attempt failed (or the retries were abandoned): report the outcome
+	// of the last attempt that actually ran.
+	return lastResult, lastErr
+}
+
+// attempt runs item once. A task of SubmitCtx gets a context that is cancelled
+// with its submission context, when a release stops waiting for running tasks,
+// or once timeout expires. A graceful release lets it finish.
+func (w *Worker) attempt(item *taskItem, timeout time.Duration, pool *GoroutinePool) (interface{}, error) {
+	t := item.task
+	if item.taskCtx != nil {
+		ctx, cancel := context.WithCancel(item.ctx)
+		defer cancel()
+		stop := context.AfterFunc(pool.stopCtx, cancel)
+		defer stop()
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if w.state != nil {
+			ctx = context.WithValue(ctx, workerStateKey{}, w.state)
+		}
+		t = func() (interface{}, error) {
+			result, err := item.taskCtx(ctx)
+			// 任务可能先于超时计时器观察到自己的ctx超时，统一报告为 ErrTaskTimeout
+			if err != nil && timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) && item.ctx.Err() == nil {
+				err = ErrTaskTimeout
+			}
 			return result, err
 		}
 	}
-	return nil, nil
+	if timeout > 0 {
+		return w.executeTaskWithTimeout(t, timeout, pool)
+	}
+	return w.executeTaskWithoutTimeout(t, pool)
+}
+
+// waitBackoff sleeps the backoff delay before the next attempt.
+// It returns false if the pool is released while waiting.
+func (w *Worker) waitBackoff(pool *GoroutinePool, attempt int) bool {
+	delay := pool.backoff.NextDelay(attempt)
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-pool.ctx.Done():
+		return false
+	}
 }
 
-func (w *Worker) executeTaskWithTimeout(t Task, pool *GoroutinePool) (interface{}, error) {
+func (w *Worker) executeTaskWithTimeout(t Task, timeout time.Duration, pool *GoroutinePool) (interface{}, error) {
 	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), pool.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	// Create a channel to receive the result of the task
-	resultChan := make(chan interface{})
-	errChan := make(chan error)
+	// Create a channel to receive the outcome of the task. It is buffered so
+	// the goroutine can always deliver and exit, even after a timeout.
+	type outcome struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan outcome, 1)
 
 	// Run the task in a separate goroutine
 	go func() {
-		res, err := t()
-		select {
-		case resultChan <- res:
-		case errChan <- err:
-		case <-ctx.Done():
-			// The context was canceled, stop the task
-			return
-		}
+		res, err := w.runTask(t, pool)
+		done <- outcome{res, err}
 	}()
 
-	var (
-		result interface{}
-		err    error
-	)
-
 	// Wait for the task to finish or for the context to timeout
 	select {
-	case result = <-resultChan:
-		err = <-errChan
-		return result, err
+	case out := <-done:
+		return out.result, out.err
 	case <-ctx.Done():
 		// The context wa timeout, the task took too long
-		return nil, errors.New("task timeout")
+		return nil, ErrTaskTimeout
 	}
 }
 
-func (w *Worker) executeTaskWithoutTimeout(t Task) (interface{}, error) {
+func (w *Worker) executeTaskWithoutTimeout(t Task, pool *GoroutinePool) (interface{}, error) {
+	return w.runTask(t, pool)
+}
+
+// runTask runs t, converting a panic into a *PanicError so the worker survives it
+func (w *Worker) runTask(t Task, pool *GoroutinePool) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			if pool.panicCallback != nil {
+				pool.panicCallback(r, stack)
+			}
+			result, err = nil, &PanicError{Recovered: r, Stack: stack}
+		}
+	}()
 	return t()
 }
 
 func (w *Worker) handleResult(result interface{}, err error, pool *GoroutinePool) {
+	if err != nil {
+		pool.stats.failed.Add(1)
+		if errors.Is(err, ErrTaskTimeout) {
+			pool.stats.timedOut.Add(1)
+		}
+	} else {
+		pool.stats.completed.Add(1)
+	}
+	if pool.results != nil && !pool.results.publish(TaskResult{Result: result, Err: err}) {
+		pool.stats.droppedResults.Add(1)
+	}
+	if pool.completionCallback != nil {
+		pool.completionCallback(result, err)
+	}
 	if err != nil && pool.errCallback != nil {
 		pool.errCallback(err)
 	} else if pool.resultCallback != nil {
@@ -0,0 +1,24 @@
+package GoroutinePool
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrWorkerInit is wrapped by the errors of a failing WithWorkerInit hook
+var ErrWorkerInit = errors.New("worker init failed")
+
+type workerStateKey struct{}
+
+// WorkerState returns the state WithWorkerInit created for the worker running
+// the task that received ctx, or nil if there is none
+func WorkerState(ctx context.Context) interface{} {
+	return ctx.Value(workerStateKey{})
+}
+
+// reportError passes an error that does not belong to a task to the error callback
+func (pool *GoroutinePool) reportError(err error) {
+	if err != nil && pool.errCallback != nil {
+		pool.errCallback(err)
+	}
+}
@@ -0,0 +1,156 @@
+package GoroutinePool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerInitTeardown(t *testing.T) {
+	var (
+		inits     atomic.Int32
+		teardowns atomic.Int32
+		mu        sync.Mutex
+		live      = map[int32]bool{}
+	)
+	pool := NewGoroutinePool(4,
+		WithMinWorkers(1),
+		WithWorkerIdleTimeout(time.Millisecond),
+		WithAdjustInterval(time.Millisecond),
+		WithWorkerInit(func() (interface{}, error) {
+			id := inits.Add(1)
+			mu.Lock()
+			live[id] = true
+			mu.Unlock()
+			return id, nil
+		}),
+		WithWorkerTeardown(func(state interface{}) {
+			teardowns.Add(1)
+			mu.Lock()
+			delete(live, state.(int32))
+			mu.Unlock()
+		}),
+	)
+	if n := inits.Load(); n != 1 {
+		t.Fatalf("expected 1 init for the minimum worker, got %d", n)
+	}
+
+	// 任务通过 ctx 拿到所在worker的状态
+	state, err := pool.SubmitCtx(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return WorkerState(ctx), nil
+	}).Get()
+	if err != nil || state != int32(1) {
+		t.Fatalf("expected the state of the only worker, got (%v, %v)", state, err)
+	}
+
+	// 扩容
+	if err := pool.Resize(4, 4); err != nil {
+		t.Fatal(err)
+	}
+	if n := inits.Load(); n != 4 {
+		t.Fatalf("expected 4 inits after scaling up, got %d", n)
+	}
+
+	// 缩容，空闲的worker被调整协程停止
+	if err := pool.Resize(1, 4); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for teardowns.Load() != 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := teardowns.Load(); n != 3 {
+		t.Fatalf("expected 3 teardowns after scaling down, got %d", n)
+	}
+
+	pool.Release()
+	if n := teardowns.Load(); n != inits.Load() {
+		t.Fatalf("expected a teardown for each of the %d inits once Release returned, got %d", inits.Load(), n)
+	}
+	if len(live) != 0 {
+		t.Fatalf("worker states left without teardown: %v", live)
+	}
+}
+
+func TestWorkerInitFailure(t *testing.T) {
+	var (
+		calls  atomic.Int32
+		mu     sync.Mutex
+		errs   []error
+		initOK atomic.Bool
+	)
+	initErr := errors.New("no session")
+	pool := NewGoroutinePool(2,
+		WithAdjustInterval(time.Millisecond),
+		WithErrCallback(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}),
+		WithWorkerInit(func() (interface{}, error) {
+			calls.Add(1)
+			if !initOK.Load() {
+				return nil, initErr
+			}
+			return "session", nil
+		}),
+	)
+	defer pool.Release()
+
+	if n := pool.GetWorkers(); n != 0 {
+		t.Fatalf("expected no worker to start when init fails, got %d", n)
+	}
+	mu.Lock()
+	// 构造时两个worker的初始化错误合并为一次回调
+	if len(errs) == 0 || !errors.Is(errs[0], ErrWorkerInit) || !errors.Is(errs[0], initErr) {
+		t.Fatalf("expected the init errors in the error callback, got %v", errs)
+	}
+	if n := len(errs[0].(interface{ Unwrap() []error }).Unwrap()); n != 2 {
+		t.Fatalf("expected an error for each of the 2 workers, got %d", n)
+	}
+	mu.Unlock()
+
+	// 初始化恢复后，调整协程补足最小数量的worker
+	initOK.Store(true)
+	deadline := time.Now().Add(time.Second)
+	for pool.GetWorkers() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the adjuster to start 2 workers, got %d", pool.GetWorkers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	result, err := pool.SubmitWithResult(func() (interface{}, error) { return "ok", nil }).Get()
+	if result != "ok" || err != nil {
+		t.Fatalf("got (%v, %v)", result, err)
+	}
+}
+
+func TestWorkerInitAlwaysFails(t *testing.T) {
+	initErr := errors.New("no session")
+	pool := NewGoroutinePool(2, WithWorkerInit(func() (interface{}, error) {
+		return nil, initErr
+	}))
+
+	// 没有worker能启动时任务以初始化错误结束，而不是一直等待
+	_, err := pool.SubmitWithResult(func() (interface{}, error) {
+		t.Error("task ran without a worker")
+		return nil, nil
+	}).Get()
+	if !errors.Is(err, ErrWorkerInit) || !errors.Is(err, initErr) {
+		t.Fatalf("expected the init error, got %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		pool.Release()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Release hung on a pool without workers")
+	}
+}